@@ -0,0 +1,27 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"sort"
+)
+
+// AllDirs returns the sorted paths of all the directories of the archive (the root excluded),
+// either explicit or implied by the paths of their content, using a single query.
+// This is useful to build a folder tree without listing the files.
+//
+// Permissions are enforced like with Tree: the directories below a directory whose
+// content can't be read are omitted.
+func (ar *arfs) AllDirs() ([]string, error) {
+	var dirs []string
+	err := ar.walkTree(".", func(path string, fi *fileinfo) error {
+		if fi.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: ".", Err: err}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
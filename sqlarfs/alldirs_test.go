@@ -0,0 +1,32 @@
+package sqlarfs_test
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestAllDirs(t *testing.T) {
+	// Only file rows, except for "x"
+	db := createDB(t, sqlarSchema)
+	for _, name := range []string{"a.txt", "subdir/c.txt", "subdir/subdir2/e.txt", "subdir/subdir2/f.txt", "subdir.txt", "x/y/z/w.txt"} {
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")})
+	}
+	insertEntries(t, db, entry{name: "x", mode: syscall.S_IFDIR | 0755})
+
+	dirs, err := newFS(db).AllDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"subdir", "subdir/subdir2", "x", "x/y", "x/y/z"}; !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("got %q, expected %q", dirs, expected)
+	}
+
+	dirs, err = openFS(t, "testdata/dir.sqlar").AllDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"subdir", "subdir/subdir2"}; !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("dir.sqlar: got %q, expected %q", dirs, expected)
+	}
+}
@@ -0,0 +1,91 @@
+package sqlarfs
+
+import (
+	"io"
+	"io/fs"
+	"syscall"
+)
+
+// BrokenEntry is an entry of the sqlar table reported by BrokenEntries.
+type BrokenEntry struct {
+	Name   string
+	Reason string
+}
+
+// BrokenEntries checks all the entries of the sqlar table and reports those that
+// have an invalid name, an invalid mode, or data that can't be decompressed to the expected size.
+//
+// This is a health check for archives: the data of every file is decompressed,
+// but the decompressed content is never kept in memory, and decompression stops
+// as soon as the expected size is exceeded.
+// Permissions are not checked. Hidden entries (see [WithEntryFilter]) are not reported.
+func (ar *arfs) BrokenEntries() ([]BrokenEntry, error) {
+	rows, err := ar.db.Query(`` +
+		`SELECT name,` + ar.modeExpr() + `,sz,data` +
+		` FROM ` + ar.table() +
+		` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var broken []BrokenEntry
+	for rows.Next() {
+		var (
+			name string
+			mode uint32
+			sz   int64
+			data []byte
+		)
+		if err := rows.Scan(&name, &mode, &sz, &data); err != nil {
+			return broken, err
+		}
+		if ar.hiddenPath(name, mode) {
+			continue
+		}
+		if reason := ar.checkEntry(name, mode, sz, data); reason != "" {
+			broken = append(broken, BrokenEntry{Name: name, Reason: reason})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return broken, err
+	}
+	return broken, rows.Close()
+}
+
+// checkEntry returns the reason why an entry is broken, or "".
+func (ar *arfs) checkEntry(name string, mode uint32, sz int64, data []byte) string {
+	if !fs.ValidPath(name) {
+		return "invalid name"
+	}
+	if !isDirMode(mode) && !isRegMode(mode) { // See sqlModeFilter
+		if mode&syscall.S_IFMT != 0 {
+			return "unsupported file type"
+		}
+		return "invalid mode"
+	}
+	if !isRegMode(mode) {
+		return ""
+	}
+	if sz < 0 {
+		return "invalid size"
+	}
+	data, err := ar.decodeData(name, data)
+	if err != nil {
+		return err.Error()
+	}
+	r, err := ar.dataReader(name, data, sz)
+	if err != nil {
+		return err.Error()
+	}
+	defer r.Close()
+	// Read at most one byte more than expected
+	n, err := io.Copy(io.Discard, io.LimitReader(r, sz+1))
+	switch {
+	case err != nil:
+		return "decompression: " + err.Error()
+	case n != sz:
+		return "size mismatch"
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+)
+
+func TestBrokenEntries(t *testing.T) {
+	content := bytes.Repeat([]byte("sqlar "), 1000)
+	compressed := deflate(t, content)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/ok.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed},
+		entry{name: "dir/truncated.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed[:len(compressed)/2]},
+		entry{name: "dir/short.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)) + 10, data: compressed},
+		entry{name: "dir/stored.txt", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("abc")},
+		entry{name: "badmode", mode: 0644, sz: 3, data: []byte("abc")},
+		entry{name: "/absolute", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("abc")},
+	)
+
+	broken, err := newFS(db).BrokenEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(broken)
+	expected := []string{"/absolute", "badmode", "dir/short.txt", "dir/truncated.txt"}
+	if len(broken) != len(expected) {
+		t.Fatalf("got %v, expected %q", broken, expected)
+	}
+	for i, b := range broken {
+		if b.Name != expected[i] || b.Reason == "" {
+			t.Errorf("got %v, expected %q", b, expected[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sqlarfs
+
+// CacheStats are the counters of the caches of metadata of an [FS], to help to tune caching.
+type CacheStats struct {
+	Dirs  CacheCounters // Cache of directories
+	Files CacheCounters // Cache of regular files. See WithReadOnlyGuarantee
+
+	Decompressors PoolCounters // Pool of DEFLATE decompressors
+}
+
+// PoolCounters are the counters of a pool of reusable resources.
+type PoolCounters struct {
+	Reused    uint64
+	Allocated uint64
+}
+
+// CacheCounters are the counters of a cache.
+type CacheCounters struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// CacheStats returns the counters of the caches of metadata.
+//
+// Every lookup by path probes the cache of directories first, so with
+// [WithReadOnlyGuarantee] the lookup of a regular file is a miss of the
+// cache of directories.
+func (ar *arfs) CacheStats() CacheStats {
+	return CacheStats{
+		Dirs:  ar.dirInfo.counters(),
+		Files: ar.fileInfo.counters(),
+		Decompressors: PoolCounters{
+			Reused:    ar.flatePool.reused.Load(),
+			Allocated: ar.flatePool.allocated.Load(),
+		},
+	}
+}
+
+func (di *dirInfoCache) counters() CacheCounters {
+	di.mu.RLock()
+	n := len(di.info)
+	di.mu.RUnlock()
+	return CacheCounters{
+		Hits:    di.hits.Load(),
+		Misses:  di.misses.Load(),
+		Entries: n,
+	}
+}
@@ -0,0 +1,41 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestCacheStats(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+
+	if _, err := fs.Stat(ar, "subdir"); err != nil {
+		t.Fatal(err)
+	}
+	cold := ar.CacheStats()
+	t.Logf("%+v", cold)
+	if cold.Dirs.Misses == 0 || cold.Dirs.Entries == 0 {
+		t.Errorf("cold: got %+v, expected a miss and an entry", cold.Dirs)
+	}
+
+	if _, err := fs.Stat(ar, "subdir"); err != nil {
+		t.Fatal(err)
+	}
+	warm := ar.CacheStats()
+	t.Logf("%+v", warm)
+	if warm.Dirs.Hits <= cold.Dirs.Hits || warm.Dirs.Misses != cold.Dirs.Misses {
+		t.Errorf("warm: got %+v, expected a hit after %+v", warm.Dirs, cold.Dirs)
+	}
+
+	// Regular files
+	ar = openFS(t, "testdata/dir.sqlar", sqlarfs.WithReadOnlyGuarantee())
+	for i := 0; i < 2; i++ {
+		if _, err := fs.Stat(ar, "a.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := ar.CacheStats(); stats.Files.Hits != 1 || stats.Files.Misses != 1 || stats.Files.Entries != 1 {
+		t.Errorf("files: got %+v", stats.Files)
+	}
+}
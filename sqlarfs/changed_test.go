@@ -0,0 +1,67 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestErrChanged(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "a.txt", mode: syscall.S_IFREG | 0644, mtime: 1000, sz: 3, data: []byte("old")})
+	replace := func(mtime int64, data string) {
+		t.Helper()
+		if _, err := db.Exec(`UPDATE sqlar SET mtime=?,sz=?,data=? WHERE name='a.txt'`, mtime, len(data), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ar := sqlarfs.New(db)
+	f, err := ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replace(2000, "new")
+	_, err = io.ReadAll(f)
+	f.Close()
+	if !errors.Is(err, sqlarfs.ErrChanged) {
+		t.Errorf("got %v, expected ErrChanged", err)
+	}
+
+	// Reopened: the new content
+	f, err = ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(b) != "new" {
+		t.Errorf("got %q, %v", b, err)
+	}
+
+	f, err = ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replace(2000, "longer")
+	if _, err := io.ReadAll(f); !errors.Is(err, sqlarfs.ErrChanged) {
+		t.Errorf("size change: got %v, expected ErrChanged", err)
+	}
+	f.Close()
+
+	// No check with the promise that the archive doesn't change
+	ar = sqlarfs.New(db, sqlarfs.WithReadOnlyGuarantee())
+	f, err = ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replace(3000, "latest")
+	b, err = io.ReadAll(f)
+	f.Close()
+	if err != nil || string(b) != "latest" {
+		t.Errorf("read-only: got %q, %v", b, err)
+	}
+}
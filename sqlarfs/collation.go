@@ -0,0 +1,44 @@
+package sqlarfs
+
+import "fmt"
+
+// WithCollation is an [Option] for [New] that applies an SQLite collation (ex: "NOCASE")
+// to the lookups of entries by name (Open, Stat, and reading of files), to get case-insensitive
+// paths for example.
+//
+// To be used by SQLite for fast lookups, an index must be declared with the same collation:
+//
+//	CREATE INDEX sqlar_name_nocase ON sqlar(name COLLATE NOCASE)
+//
+// Without such an index, every lookup is a full scan of the sqlar table.
+// The listing of directories relies on the LIKE operator, which is not affected by
+// the collation (LIKE is case-insensitive for ASCII characters by default).
+func WithCollation(name string) Option {
+	if name == "" {
+		panic(fmt.Errorf("sqlarfs.WithCollation: empty collation name"))
+	}
+	if !isIdentifier(name) {
+		panic(fmt.Errorf("sqlarfs.WithCollation: invalid collation name %q", name))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.collation = name
+	})
+}
+
+// isIdentifier returns true if s is a plain SQL identifier, that can be used in queries without quoting.
+func isIdentifier(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// collate returns the SQL COLLATE clause to append to comparisons of names.
+func (ar *arfs) collate() string {
+	if ar.collation == "" {
+		return ""
+	}
+	return ` COLLATE ` + ar.collation
+}
@@ -0,0 +1,84 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestCollation(t *testing.T) {
+	db := createDB(t, sqlarSchema, `CREATE INDEX sqlar_name_nocase ON sqlar(name COLLATE NOCASE)`)
+	insertEntries(t, db,
+		entry{name: "Dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "Dir/ReadMe.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("hello")},
+	)
+
+	if _, err := fs.Stat(sqlarfs.New(db), "dir/README.TXT"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("without collation: got %v, expected ErrNotExist", err)
+	}
+
+	ar := sqlarfs.New(db, sqlarfs.WithCollation("NOCASE"))
+	b, err := fs.ReadFile(ar, "dir/README.TXT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("got %q", b)
+	}
+
+	// Check that SQLite uses the index for the lookup query of stat
+	var path string
+	if err := db.QueryRow(`SELECT file FROM pragma_database_list WHERE name='main'`).Scan(&path); err != nil {
+		t.Fatal(err)
+	}
+	countingDB, counter := openCountingDB(t, path)
+	ar = sqlarfs.New(countingDB, sqlarfs.WithCollation("NOCASE"))
+	if _, err := fs.Stat(ar, "."); err != nil {
+		t.Fatal(err)
+	}
+	before := counter.queries.Load()
+	if _, err := fs.Stat(ar, "DIR"); err != nil {
+		t.Fatal(err)
+	}
+	if n := counter.queries.Load() - before; n != 1 {
+		t.Fatalf("stat: got %d queries, expected 1", n)
+	}
+	query := counter.last.Load().(string)
+	t.Log(query)
+	rows, err := db.Query(`EXPLAIN QUERY PLAN `+query, "DIR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notused, &detail); err != nil {
+			t.Fatal(err)
+		}
+		plan = append(plan, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(plan)
+	if !strings.Contains(strings.Join(plan, "\n"), "sqlar_name_nocase") {
+		t.Errorf("index not used: %q", plan)
+	}
+
+	for _, name := range []string{"", "NO CASE", "x'"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: panic expected", name)
+				}
+			}()
+			sqlarfs.WithCollation(name)
+		}()
+	}
+}
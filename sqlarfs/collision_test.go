@@ -0,0 +1,66 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestFileDirCollision checks the behavior when a file has the same path as a
+// directory implied by other entries: the file wins.
+func TestFileDirCollision(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("t")},
+		entry{name: "a/b", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("b")},
+		entry{name: "a/c/d", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("d")},
+	)
+	ar := newFS(db)
+
+	entries, err := ar.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "a" || entries[0].IsDir() || entries[1].Name() != "a.txt" {
+		t.Fatalf("got %v", entries)
+	}
+
+	if _, err := ar.Stat("a/b"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("a/b: got %v, expected fs.ErrNotExist", err)
+	}
+
+	if err := fstest.TestFS(ar, "a", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	ar.DirEntries(".")(func(d fs.DirEntry, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, d.Name())
+		return true
+	})
+	if len(names) != 2 {
+		t.Errorf("DirEntries: got %q", names)
+	}
+
+	var buf bytes.Buffer
+	if err := sqlarfs.WriteTreeJSON(&buf, ar, "."); err != nil {
+		t.Fatal(err)
+	}
+	var tree jsonNode
+	if err := json.Unmarshal(buf.Bytes(), &tree); err != nil {
+		t.Fatal(err)
+	}
+	if paths := tree.paths("", nil); len(paths) != 2 {
+		t.Errorf("WriteTreeJSON: got %q", paths)
+	}
+}
@@ -0,0 +1,53 @@
+package sqlarfs
+
+import (
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WithSQLiteExtCompat is an [Option] for [New] that decompresses data exactly like the
+// sqlar_uncompress SQL function of the [sqlar extension] of SQLite (used by the sqlite3
+// command-line tool to create archives): compressed data is a zlib stream (RFC 1950).
+//
+// By default, compressed data is read as a raw DEFLATE stream (RFC 1951), or with a
+// decompressor registered with [RegisterDecompressor] if it starts with its magic.
+// With this option, registered decompressors and [WithFlateDictionary] are ignored,
+// and OpenCRC and CopyRawTo report [MethodUnknown] for compressed data.
+// As by default, data is stored as is if its size is the size of the file.
+// Use CompressionFormat to check the format of the compressed data of an archive.
+//
+// [sqlar extension]: https://sqlite.org/src/file/ext/misc/sqlar.c
+func WithSQLiteExtCompat() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.sqliteExtCompat = true
+	})
+}
+
+// newZlibReader returns a reader of the content of zlib data.
+// The Adler-32 checksum at the end of the data is checked: [ErrCorrupt] is returned on mismatch.
+func newZlibReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, zlibError(err)
+	}
+	return &zlibReader{zr}, nil
+}
+
+type zlibReader struct {
+	io.ReadCloser
+}
+
+func (r *zlibReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	return n, zlibError(err)
+}
+
+// zlibError wraps the errors of invalid zlib data with [ErrCorrupt].
+func zlibError(err error) error {
+	if errors.Is(err, zlib.ErrChecksum) || errors.Is(err, zlib.ErrHeader) || errors.Is(err, zlib.ErrDictionary) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: zlib: %w", ErrCorrupt, err)
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestSQLiteExtCompat reads an archive created by the sqlite3 command-line tool,
+// with files compressed with zlib.
+func TestSQLiteExtCompat(t *testing.T) {
+	ar := openFS(t, "testdata/zlib.sqlar", sqlarfs.WithSQLiteExtCompat())
+	if err := fstest.TestFS(ar, "lorem.txt", "small.txt"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"lorem.txt", "small.txt"} {
+		expected, err := os.ReadFile("testdata/zlib/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, expected) {
+			t.Errorf("%s: content mismatch", name)
+		}
+	}
+	if format, err := ar.CompressionFormat(); err != nil || format != sqlarfs.FormatZlib {
+		t.Errorf("CompressionFormat: got %v, %v", format, err)
+	}
+	if _, method, err := ar.CopyRawTo("lorem.txt", &bytes.Buffer{}); err != nil || method != sqlarfs.MethodUnknown {
+		t.Errorf("CopyRawTo: got method %d, %v", method, err)
+	}
+
+	// By default, compressed data is raw DEFLATE
+	if _, err := fs.ReadFile(openFS(t, "testdata/zlib.sqlar"), "lorem.txt"); err == nil {
+		t.Error("lorem.txt: error expected without WithSQLiteExtCompat")
+	}
+
+	// The checksum of the zlib stream is checked
+	content := bytes.Repeat([]byte("zlib "), 100)
+	data := zlibCompress(t, content)
+	data[len(data)-1] ^= 0xff
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "corrupt.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data})
+	if _, err := fs.ReadFile(sqlarfs.New(db, sqlarfs.WithSQLiteExtCompat()), "corrupt.txt"); !errors.Is(err, sqlarfs.ErrCorrupt) {
+		t.Errorf("corrupt.txt: got %v, expected ErrCorrupt", err)
+	}
+}
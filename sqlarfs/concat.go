@@ -0,0 +1,167 @@
+package sqlarfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// Concat returns an [io/fs.FS] that presents the content of several filesystems (usually
+// parts of a large archive split in several [FS]) as a single tree.
+//
+// The parts must have disjoint namespaces: the only paths they may share are directories,
+// whose content is merged. As this is assumed, a lookup of a file returns the first match
+// without checking the other parts for a conflicting entry. The metadata of a shared
+// directory is the one of the first part.
+func Concat(parts ...fs.FS) fs.FS {
+	return &concatFS{parts: parts}
+}
+
+type concatFS struct {
+	parts []fs.FS
+}
+
+var (
+	_ fs.StatFS    = (*concatFS)(nil)
+	_ fs.ReadDirFS = (*concatFS)(nil)
+)
+
+// stat returns the info of name from the first part that has it.
+func (c *concatFS) stat(name string) (fs.FileInfo, int, error) {
+	if !fs.ValidPath(name) {
+		return nil, -1, fs.ErrInvalid
+	}
+	for i, part := range c.parts {
+		info, err := fs.Stat(part, name)
+		if err == nil {
+			return info, i, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, -1, err
+		}
+	}
+	if name == "." {
+		// No parts
+		return &fileinfoRoot, -1, nil
+	}
+	return nil, -1, fs.ErrNotExist
+}
+
+// Stat implements interface [fs.StatFS].
+func (c *concatFS) Stat(name string) (fs.FileInfo, error) {
+	info, _, err := c.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: unwrapPathError(err)}
+	}
+	return info, nil
+}
+
+// Open implements interface [fs.FS].
+func (c *concatFS) Open(name string) (fs.File, error) {
+	info, i, err := c.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: unwrapPathError(err)}
+	}
+	if info.IsDir() {
+		return &concatDir{fs: c, info: info, path: name}, nil
+	}
+	return c.parts[i].Open(name)
+}
+
+// ReadDir implements interface [fs.ReadDirFS].
+//
+// The entries of a directory shared by several parts are merged.
+func (c *concatFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	var list []fs.DirEntry
+	found := name == "."
+	for _, part := range c.parts {
+		entries, err := fs.ReadDir(part, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		list = append(list, entries...)
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	// Drop the duplicates of shared directories
+	merged := list[:0]
+	for _, e := range list {
+		if n := len(merged); n > 0 && merged[n-1].Name() == e.Name() {
+			continue
+		}
+		merged = append(merged, e)
+	}
+	if merged == nil {
+		merged = []fs.DirEntry{}
+	}
+	return merged, nil
+}
+
+// unwrapPathError returns the error wrapped by an [*io/fs.PathError], to wrap it with another path.
+func unwrapPathError(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
+
+// concatDir is a directory of a [concatFS].
+type concatDir struct {
+	fs      *concatFS
+	info    fs.FileInfo
+	path    string
+	entries []fs.DirEntry // nil until read
+}
+
+// Stat implements interface [fs.File].
+func (d *concatDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// Read implements interface [fs.File].
+func (d *concatDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// Close implements interface [fs.File].
+func (d *concatDir) Close() error {
+	return nil
+}
+
+// ReadDir implements interface [fs.ReadDirFile].
+func (d *concatDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		var err error
+		d.entries, err = d.fs.ReadDir(d.path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n <= 0 {
+		e := d.entries
+		d.entries = []fs.DirEntry{}
+		return e, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	e := d.entries[:n]
+	d.entries = d.entries[n:]
+	return e, nil
+}
@@ -0,0 +1,34 @@
+package sqlarfs_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestConcat(t *testing.T) {
+	ar := sqlarfs.Concat(
+		openFS(t, "testdata/simple.sqlar"),
+		openFS(t, "testdata/dir.sqlar"),
+	)
+	if err := fstest.TestFS(ar,
+		"foo.txt", "bar.txt",
+		"a.txt", "b.txt", "subdir", "subdir/c.txt", "subdir/d.txt", "subdir/subdir2", "subdir/subdir2/e.txt", "subdir/subdir2/f.txt",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shared directories are merged
+	ar = sqlarfs.Concat(
+		sqlarfs.Mount(openFS(t, "testdata/simple.sqlar"), "subdir"),
+		openFS(t, "testdata/dir.sqlar"),
+	)
+	if err := fstest.TestFS(ar, "a.txt", "subdir/foo.txt", "subdir/c.txt", "subdir/subdir2/e.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(sqlarfs.Concat()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package sqlarfs
+
+import "time"
+
+// Config is the effective configuration of an [FS], as set with the [Option]s of [New].
+// Fields are the zero value for options that were not set.
+type Config struct {
+	PermMask PermMask // See PermOwner, PermGroup, PermOthers, PermAny
+	PermUser *PermUser
+
+	MaxDecompressRatio float64       // 0: no limit
+	MaxDecompressBytes int64         // 0: no limit
+	NegativeCacheTTL   time.Duration // 0: no negative cache
+	MaxConcurrentReads int           // 0: no limit
+	ReadRateLimit      int64         // Bytes per second. 0: no limit
+
+	ReadOnlyGuarantee       bool
+	AssumeRegularWhenNoType bool
+	VerifyHash              bool
+	TrailingSlash           bool
+	LazyReadDir             bool
+	SQLiteExtCompat         bool
+	TextNormalization       bool
+	SparseReads             bool
+	LenientRead             bool
+
+	DataEncoding   DataEncoding
+	ModeConvention Convention
+	Collation      string         // "": default collation of the column
+	Location       *time.Location // nil: time.Local
+	LikeEscape     string         // "": '§'
+	NameColumn     string         // "": "name"
+
+	EntryFilter     bool // A filter is set with WithEntryFilter
+	ReadTransform   bool // A transform is set with WithReadTransform
+	FlateDictionary bool // A dictionary is set with WithFlateDictionary
+	ReadProgress    bool // A callback is set with WithReadProgress
+
+	// Names of the decompressors registered with RegisterDecompressor (including "bzip2").
+	// Decompressors are shared by all instances.
+	Decompressors []string
+}
+
+// PermUser is the user set with [WithPermForUser].
+type PermUser struct {
+	UID, GID uint32
+}
+
+// Config returns the effective configuration, to check the result of the options passed to [New].
+func (ar *arfs) Config() Config {
+	cfg := Config{
+		PermMask:                ar.permMask,
+		MaxDecompressRatio:      ar.maxDecompressRatio,
+		MaxDecompressBytes:      ar.maxDecompressBytes,
+		NegativeCacheTTL:        ar.negativeCacheTTL,
+		MaxConcurrentReads:      ar.maxConcurrentReads,
+		ReadRateLimit:           ar.readRateLimit,
+		ReadOnlyGuarantee:       ar.readOnly,
+		AssumeRegularWhenNoType: ar.assumeRegular,
+		VerifyHash:              ar.verifyHash,
+		TrailingSlash:           ar.trimTrailingSlash,
+		LazyReadDir:             ar.lazyReadDir,
+		SQLiteExtCompat:         ar.sqliteExtCompat,
+		TextNormalization:       ar.textNormalization,
+		SparseReads:             ar.sparseReads,
+		LenientRead:             ar.lenientRead,
+		DataEncoding:            ar.dataEncoding,
+		ModeConvention:          ar.modeConvention,
+		Collation:               ar.collation,
+		NameColumn:              ar.nameColumn,
+		Location:                ar.location,
+		EntryFilter:             ar.keep != nil,
+		ReadTransform:           ar.transform != nil,
+		FlateDictionary:         ar.flateDict != nil,
+		ReadProgress:            ar.progress != nil,
+	}
+	if ar.likeEscape != nil {
+		cfg.LikeEscape = ar.likeEscape.char
+	}
+	if ar.permUser != nil {
+		cfg.PermUser = &PermUser{UID: ar.permUser.uid, GID: ar.permUser.gid}
+	}
+	decompressors.mu.RLock()
+	defer decompressors.mu.RUnlock()
+	for _, d := range decompressors.list {
+		cfg.Decompressors = append(cfg.Decompressors, d.name)
+	}
+	return cfg
+}
@@ -0,0 +1,88 @@
+package sqlarfs_test
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestConfig(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+
+	cfg := newFS(db).Config()
+	decompressors := cfg.Decompressors
+	if len(decompressors) == 0 || decompressors[0] != "bzip2" {
+		t.Errorf("got decompressors %v", decompressors)
+	}
+	if expected := (sqlarfs.Config{PermMask: sqlarfs.PermAny, Decompressors: decompressors}); !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("default: got %+v, expected %+v", cfg, expected)
+	}
+
+	loc := time.FixedZone("UTC+2", 2*3600)
+	cfg = newFS(db,
+		sqlarfs.PermOthers,
+		sqlarfs.WithMaxDecompressRatio(100),
+		sqlarfs.WithMaxDecompressBytes(1<<20),
+		sqlarfs.WithNegativeCache(time.Minute),
+		sqlarfs.WithMaxConcurrentReads(4),
+		sqlarfs.WithReadRateLimit(1<<20),
+		sqlarfs.WithReadOnlyGuarantee(),
+		sqlarfs.WithAssumeRegularWhenNoType(),
+		sqlarfs.WithVerifyHash(),
+		sqlarfs.WithTrailingSlash(),
+		sqlarfs.WithLazyReadDir(),
+		sqlarfs.WithSQLiteExtCompat(),
+		sqlarfs.WithTextNormalization(),
+		sqlarfs.WithSparseReads(),
+		sqlarfs.WithLenientRead(),
+		sqlarfs.WithLikeEscape('\\'),
+		sqlarfs.WithDataEncoding(sqlarfs.Base64),
+		sqlarfs.WithModeConvention(sqlarfs.ConventionGoFileMode),
+		sqlarfs.WithCollation("NOCASE"),
+		sqlarfs.WithNameColumn("path"),
+		sqlarfs.WithLocation(loc),
+		sqlarfs.WithEntryFilter(func(string, uint32) bool { return true }),
+		sqlarfs.WithReadTransform(func(_ string, r io.Reader) (io.Reader, error) { return r, nil }),
+		sqlarfs.WithFlateDictionary([]byte("dict")),
+		sqlarfs.WithReadProgress(func(string, int64, int64) {}),
+	).Config()
+	expected := sqlarfs.Config{
+		PermMask:                sqlarfs.PermOthers,
+		MaxDecompressRatio:      100,
+		MaxDecompressBytes:      1 << 20,
+		NegativeCacheTTL:        time.Minute,
+		MaxConcurrentReads:      4,
+		ReadRateLimit:           1 << 20,
+		ReadOnlyGuarantee:       true,
+		AssumeRegularWhenNoType: true,
+		VerifyHash:              true,
+		TrailingSlash:           true,
+		LazyReadDir:             true,
+		SQLiteExtCompat:         true,
+		TextNormalization:       true,
+		SparseReads:             true,
+		LenientRead:             true,
+		DataEncoding:            sqlarfs.Base64,
+		ModeConvention:          sqlarfs.ConventionGoFileMode,
+		Collation:               "NOCASE",
+		NameColumn:              "path",
+		Location:                loc,
+		LikeEscape:              `\`,
+		EntryFilter:             true,
+		ReadTransform:           true,
+		FlateDictionary:         true,
+		ReadProgress:            true,
+		Decompressors:           decompressors,
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("got %+v, expected %+v", cfg, expected)
+	}
+
+	cfg = newFS(db, sqlarfs.WithPermForUser(1000, 100)).Config()
+	if cfg.PermMask != sqlarfs.PermAny || cfg.PermUser == nil || *cfg.PermUser != (sqlarfs.PermUser{UID: 1000, GID: 100}) {
+		t.Errorf("WithPermForUser: got %+v", cfg)
+	}
+}
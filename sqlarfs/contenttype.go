@@ -0,0 +1,42 @@
+package sqlarfs
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// ContentType returns the MIME type of file name in ar.
+//
+// The type is determined from the file extension with [mime.TypeByExtension] or, as a fallback,
+// by sniffing the first 512 bytes of the content with [net/http.DetectContentType].
+// The file is checked to exist even if its extension is enough.
+func ContentType(ar fs.FS, name string) (string, error) {
+	info, err := fs.Stat(ar, name)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if ext := path.Ext(name); ext != "" {
+		if typ := mime.TypeByExtension(ext); typ != "" {
+			return typ, nil
+		}
+	}
+
+	f, err := ar.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var buf [512]byte
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
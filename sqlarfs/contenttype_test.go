@@ -0,0 +1,52 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestContentType(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	html := []byte("<!DOCTYPE html><html><body>Hello</body></html>")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	insertEntries(t, db,
+		entry{name: "index.html", mode: syscall.S_IFREG | 0644, sz: int64(len(html)), data: html},
+		entry{name: "config.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "page", mode: syscall.S_IFREG | 0644, sz: int64(len(html)), data: html},
+		entry{name: "image.unknown-ext", mode: syscall.S_IFREG | 0644, sz: int64(len(png)), data: png},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := sqlarfs.New(db)
+
+	for _, tc := range []struct {
+		name string
+		typ  string
+	}{
+		{"index.html", "text/html; charset=utf-8"},
+		{"config.json", "application/json"},
+		{"page", "text/html; charset=utf-8"},
+		{"image.unknown-ext", "image/png"},
+	} {
+		typ, err := sqlarfs.ContentType(ar, tc.name)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if typ != tc.typ {
+			t.Errorf("%s: got %q, expected %q", tc.name, typ, tc.typ)
+		}
+	}
+
+	for _, name := range []string{"missing", "missing.html"} {
+		if _, err := sqlarfs.ContentType(ar, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: got %v, expected fs.ErrNotExist", name, err)
+		}
+	}
+	if _, err := sqlarfs.ContentType(ar, "dir"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("dir: got %v, expected fs.ErrInvalid", err)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+)
+
+// Convention is the convention used by the producer of an archive to store the type of
+// entries in the 'mode' column. See [StorageFS] and [WithModeConvention].
+type Convention int
+
+const (
+	// ConventionUnix is the standard: the st_mode of stat(2), with S_IFDIR (0040000) for directories
+	// and S_IFREG (0100000) for regular files. The values are the same on Linux, the BSDs and macOS.
+	ConventionUnix Convention = iota
+	// ConventionGoFileMode is the [io/fs.FileMode] of Go stored as is by naive producers:
+	// [fs.ModeDir] (1<<31) for directories and no type bits for regular files.
+	ConventionGoFileMode
+	// ConventionNoType is the permission bits only, without file type bits.
+	ConventionNoType
+	// ConventionMixed is reported when the modes of the entries follow different conventions.
+	ConventionMixed
+)
+
+func (c Convention) String() string {
+	switch c {
+	case ConventionUnix:
+		return "unix"
+	case ConventionGoFileMode:
+		return "go-filemode"
+	case ConventionNoType:
+		return "no-type"
+	case ConventionMixed:
+		return "mixed"
+	default:
+		return fmt.Sprintf("Convention(%d)", int(c))
+	}
+}
+
+// modeConventionSample is the number of entries whose mode is checked by ModeConvention.
+const modeConventionSample = 1000
+
+// sqlGoFileMode converts an [io/fs.FileMode] to a Unix mode. Other types than directories
+// and regular files, and the setuid, setgid and sticky bits, are dropped.
+const (
+	sqlGoFileMode = `CASE` +
+		` WHEN mode&2147483648<>0 THEN 16384|(mode&511)` + // 2147483648 = fs.ModeDir, 16384 = syscall.S_IFDIR
+		` WHEN mode&254279680=0 THEN 32768|(mode&511)` + // 254279680 = fs.ModeType&^fs.ModeDir, 32768 = syscall.S_IFREG
+		` ELSE mode&511` +
+		` END`
+	sqlGoFileModeFilter    = `(mode&2147483648<>0 OR mode&254279680=0)`
+	sqlGoFileModeFilterReg = `(mode&2401763328=0)` // 2401763328 = fs.ModeType
+)
+
+// ModeConvention checks the modes of (at most) the first thousand entries of the archive and
+// reports the convention used by the producer of the archive to store the type of entries.
+// Entries of archives that don't follow [ConventionUnix] are not handled as expected: use
+// [WithModeConvention] to read them. An empty archive is reported as ConventionUnix.
+//
+// Regular files of [ConventionGoFileMode] have no type bits: an archive with only regular
+// files in that convention is reported as [ConventionNoType].
+func (ar *arfs) ModeConvention() (Convention, error) {
+	var unix, goDirs, noType sql.NullInt64
+	err := ar.db.QueryRow(``+
+		`SELECT`+
+		` SUM(mode&2147483648=0 AND mode&61440<>0),`+ // 61440 = syscall.S_IFMT
+		` SUM(mode&2147483648<>0),`+
+		` SUM(mode&2147483648=0 AND mode&61440=0)`+
+		` FROM (SELECT mode FROM `+ar.table()+` LIMIT ?)`,
+		modeConventionSample,
+	).Scan(&unix, &goDirs, &noType)
+	if err != nil {
+		return 0, &fs.PathError{Op: "stat", Path: ".", Err: err}
+	}
+	switch {
+	case goDirs.Int64 == 0 && noType.Int64 == 0:
+		return ConventionUnix, nil
+	case unix.Int64 > 0:
+		return ConventionMixed, nil
+	case goDirs.Int64 > 0:
+		return ConventionGoFileMode, nil
+	default:
+		return ConventionNoType, nil
+	}
+}
+
+// WithModeConvention is an [Option] for [New] that reads the 'mode' column with convention c
+// (see [StorageFS]), to handle archives from producers that don't store Unix modes.
+// ConventionNoType is the same as [WithAssumeRegularWhenNoType].
+//
+// WithModeConvention panics if c is ConventionMixed or unknown.
+func WithModeConvention(c Convention) Option {
+	switch c {
+	case ConventionUnix, ConventionGoFileMode, ConventionNoType:
+	default:
+		panic(fmt.Errorf("sqlarfs.WithModeConvention: invalid convention %v", c))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.modeConvention = c
+		if c == ConventionNoType {
+			ar.assumeRegular = true
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestModeConvention(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected sqlarfs.Convention
+	}{
+		{"testdata/dir.sqlar", sqlarfs.ConventionUnix},
+		{"testdata/perms.sqlar", sqlarfs.ConventionUnix},
+		{"testdata/empty.sqlar", sqlarfs.ConventionUnix},
+		{"testdata/gomode.sqlar", sqlarfs.ConventionGoFileMode},
+	} {
+		c, err := openFS(t, tc.path).ModeConvention()
+		if err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+		} else if c != tc.expected {
+			t.Errorf("%s: got %v, expected %v", tc.path, c, tc.expected)
+		}
+	}
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "a.txt", mode: 0644, sz: 1, data: []byte("a")})
+	if c, err := newFS(db).ModeConvention(); err != nil || c != sqlarfs.ConventionNoType {
+		t.Errorf("no type: got %v, %v", c, err)
+	}
+	insertEntries(t, db, entry{name: "b.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("b")})
+	if c, err := newFS(db).ModeConvention(); err != nil || c != sqlarfs.ConventionMixed {
+		t.Errorf("mixed: got %v, %v", c, err)
+	}
+}
+
+func TestWithModeConvention(t *testing.T) {
+	files := []string{"a.txt", "b.txt", "subdir", "subdir/c.txt", "subdir/d.txt", "subdir/subdir2", "subdir/subdir2/e.txt", "subdir/subdir2/f.txt"}
+
+	// Without the option, the files look broken, and only the directory implied by their paths remains
+	if entries, err := fs.ReadDir(openFS(t, "testdata/gomode.sqlar"), "."); err != nil || names(entries) != "subdir" {
+		t.Errorf("default: got %q, %v", names(entries), err)
+	}
+
+	ar := openFS(t, "testdata/gomode.sqlar", sqlarfs.WithModeConvention(sqlarfs.ConventionGoFileMode))
+	if err := fstest.TestFS(ar, files...); err != nil {
+		t.Fatal(err)
+	}
+	ref := openFS(t, "testdata/dir.sqlar")
+	for _, name := range files {
+		info, err := fs.Stat(ar, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := fs.Stat(ref, name)
+		if info.Mode() != expected.Mode() || info.Size() != expected.Size() {
+			t.Errorf("%s: got %v %d, expected %v %d", name, info.Mode(), info.Size(), expected.Mode(), expected.Size())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic expected")
+		}
+	}()
+	sqlarfs.WithModeConvention(sqlarfs.ConventionMixed)
+}
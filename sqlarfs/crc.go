@@ -0,0 +1,107 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"io/fs"
+)
+
+// Method identifies how the content of a file is stored.
+// The values are the compression methods of the [ZIP format].
+//
+// [ZIP format]: https://pkware.cachefly.net/webdocs/casestudies/APPNOTE.TXT
+type Method uint16
+
+const (
+	MethodStore   Method = 0      // Not compressed
+	MethodDeflate Method = 8      // Raw DEFLATE (RFC 1951), the default compression of this package
+	MethodBzip2   Method = 12     // bzip2. See RegisterDecompressor
+	MethodUnknown Method = 0xFFFF // gzip, zlib (see WithSQLiteExtCompat), or a format registered with RegisterDecompressor
+)
+
+// OpenCRC returns a reader of the data of a regular file as stored in the archive
+// (compressed or not) with its storage method, and the size and CRC-32 (IEEE) of its content.
+//
+// This allows to copy the compressed data as is into a ZIP file, which requires
+// the CRC-32 of the uncompressed content: compressed data is decompressed once to
+// compute the checksum, but isn't compressed again. The limits of decompression
+// (see [WithMaxDecompressBytes]) and hash verification (see [WithVerifyHash]) apply.
+func (ar *arfs) OpenCRC(name string) (raw io.ReadCloser, method Method, uncompressedSize int64, crc uint32, err error) {
+	info, data, sum, err := ar.rawData(name)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	r, err := ar.contentReader(name, data, info.sz, sum)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	h := crc32.NewIEEE()
+	n, err := io.Copy(h, r)
+	r.Close()
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	if n != info.sz {
+		return nil, 0, 0, 0, &fs.PathError{Op: "read", Path: name, Err: ErrCorrupt}
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), ar.rawMethod(data, info.sz), n, h.Sum32(), nil
+}
+
+// CopyRawTo writes the data of regular file name to w as stored in the archive
+// (compressed or not), and reports its storage method.
+//
+// Unlike OpenCRC, the data is neither decompressed nor verified: this is intended
+// for the byte-exact replication of entries. Permissions still apply.
+// With [WithDataEncoding], the decoded data is written.
+func (ar *arfs) CopyRawTo(name string, w io.Writer) (n int64, method Method, err error) {
+	info, data, _, err := ar.rawData(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	nw, err := w.Write(data)
+	if err == nil && nw < len(data) {
+		err = io.ErrShortWrite
+	}
+	return int64(nw), ar.rawMethod(data, info.sz), err
+}
+
+// rawData checks that name is a regular file that can be read, and
+// returns its data as stored in the archive and its expected hash (see [WithVerifyHash]).
+func (ar *arfs) rawData(name string) (info *fileinfo, data []byte, sum []byte, err error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		info, err = ar.statRoot()
+	} else {
+		info, err = ar.stat(name)
+	}
+	if err != nil {
+		return nil, nil, nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if info.IsDir() {
+		return nil, nil, nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if !ar.canRead(info.mode) {
+		return nil, nil, nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrPermission}
+	}
+	data, sum, err = ar.queryData(name, info)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return info, data, sum, nil
+}
+
+// rawMethod returns the storage method of data, the value of the 'data' column of
+// a file of sz bytes.
+func (ar *arfs) rawMethod(data []byte, sz int64) Method {
+	if int64(len(data)) < sz {
+		if ar.sqliteExtCompat {
+			return MethodUnknown
+		}
+		return compressionMethod(data)
+	}
+	return MethodStore
+}
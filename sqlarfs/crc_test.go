@@ -0,0 +1,107 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenCRC(t *testing.T) {
+	content := bytes.Repeat([]byte("Hello world\n"), 100)
+	compressed := deflate(t, content)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "compressed.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := newFS(db)
+
+	for _, tc := range []struct {
+		name   string
+		method sqlarfs.Method
+		raw    []byte
+	}{
+		{"stored.txt", sqlarfs.MethodStore, content},
+		{"compressed.txt", sqlarfs.MethodDeflate, compressed},
+	} {
+		raw, method, size, crc, err := ar.OpenCRC(tc.name)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		b, err := io.ReadAll(raw)
+		raw.Close()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if method != tc.method {
+			t.Errorf("%s: got method %d, expected %d", tc.name, method, tc.method)
+		}
+		if !bytes.Equal(b, tc.raw) {
+			t.Errorf("%s: raw data mismatch", tc.name)
+		}
+		if size != int64(len(content)) {
+			t.Errorf("%s: got size %d, expected %d", tc.name, size, len(content))
+		}
+		if expected := crc32.ChecksumIEEE(content); crc != expected {
+			t.Errorf("%s: got CRC %08x, expected %08x", tc.name, crc, expected)
+		}
+	}
+
+	for _, name := range []string{"dir", "missing.txt"} {
+		if _, _, _, _, err := ar.OpenCRC(name); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
+
+func TestCopyRawTo(t *testing.T) {
+	content := bytes.Repeat([]byte("Hello world\n"), 100)
+	compressed := deflate(t, content)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "compressed.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed},
+		entry{name: "private.txt", mode: syscall.S_IFREG | 0600, sz: int64(len(content)), data: compressed},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := newFS(db, sqlarfs.PermOthers)
+
+	for _, tc := range []struct {
+		name   string
+		method sqlarfs.Method
+	}{
+		{"stored.txt", sqlarfs.MethodStore},
+		{"compressed.txt", sqlarfs.MethodDeflate},
+	} {
+		var data []byte
+		if err := db.QueryRow(`SELECT data FROM sqlar WHERE name=?`, tc.name).Scan(&data); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		n, method, err := ar.CopyRawTo(tc.name, &buf)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if method != tc.method {
+			t.Errorf("%s: got method %d, expected %d", tc.name, method, tc.method)
+		}
+		if n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("%s: written bytes differ from the data column", tc.name)
+		}
+	}
+
+	for _, name := range []string{"dir", "missing.txt", "private.txt"} {
+		if _, _, err := ar.CopyRawTo(name, io.Discard); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sqlarfs
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/fs"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes to w an inventory of the entries of ar (the root excluded) as CSV,
+// with a header row and the columns:
+//
+//	path,type,mode,size,stored_size,mtime
+//
+// type is "dir" or "file", mode has the octal permission bits ("0644"), stored_size is
+// the size of the data in the archive (compressed or not), and mtime is in RFC 3339 format (UTC).
+//
+// If ar is an [FS] created by [New], the inventory is built from a single query, in
+// depth-first order (the entries of a directory are not necessarily in lexical order).
+// Otherwise ar is walked with [fs.WalkDir] and stored_size is empty.
+// The content of directories that are not accessible under the permission mask is omitted.
+func WriteCSV(w io.Writer, ar fs.FS) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"path", "type", "mode", "size", "stored_size", "mtime"})
+	record := func(p string, info fs.FileInfo, stored string) error {
+		typ := "file"
+		if info.IsDir() {
+			typ = "dir"
+		}
+		return cw.Write([]string{
+			p,
+			typ,
+			"0" + strconv.FormatUint(uint64(info.Mode().Perm()), 8),
+			strconv.FormatInt(info.Size(), 10),
+			stored,
+			info.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+
+	if a, ok := ar.(*arfs); ok {
+		err := a.walkTree(".", func(path string, fi *fileinfo) error {
+			return record(path, fi, strconv.FormatInt(fi.stored, 10))
+		})
+		if err != nil {
+			return &fs.PathError{Op: "readdir", Path: ".", Err: err}
+		}
+	} else {
+		err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrPermission) {
+					return fs.SkipDir
+				}
+				return err
+			}
+			if path == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return record(path, info, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
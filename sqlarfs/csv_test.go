@@ -0,0 +1,81 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestWriteCSV(t *testing.T) {
+	content := bytes.Repeat([]byte("Hello world\n"), 100)
+	compressed := deflate(t, content)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a,b.txt", mode: syscall.S_IFREG | 0644, mtime: 1700000000, sz: 1, data: []byte("x")},
+		entry{name: `dir/"quoted".txt`, mode: syscall.S_IFREG | 0600, sz: int64(len(content)), data: compressed},
+		entry{name: "implied/c.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+
+	var buf bytes.Buffer
+	if err := sqlarfs.WriteCSV(&buf, sqlarfs.New(db)); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%s", buf.Bytes())
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 {
+		t.Fatalf("got %d records, expected 6", len(records))
+	}
+	byPath := make(map[string][]string)
+	for _, r := range records[1:] {
+		byPath[r[0]] = r
+	}
+	for _, expected := range [][]string{
+		{"a,b.txt", "file", "0644", "1", "1", "2023-11-14T22:13:20Z"},
+		{`dir/"quoted".txt`, "file", "0600", strconv.Itoa(len(content)), strconv.Itoa(len(compressed)), "1970-01-01T00:00:00Z"},
+		{"dir", "dir", "0755", "0", "0", "1970-01-01T00:00:00Z"},
+		{"implied", "dir", "0555", "0", "0", "1970-01-01T00:00:00Z"},
+		{"implied/c.txt", "file", "0644", "1", "1", "1970-01-01T00:00:00Z"},
+	} {
+		if got := byPath[expected[0]]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+	}
+
+	// Permissions
+	buf.Reset()
+	if err := sqlarfs.WriteCSV(&buf, sqlarfs.New(db, sqlarfs.PermOthers)); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := csv.NewReader(&buf).ReadAll(); err != nil || len(records) != 6 {
+		t.Errorf("PermOthers: got %d records, %v", len(records), err)
+	}
+
+	// Generic FS
+	buf.Reset()
+	if err := sqlarfs.WriteCSV(&buf, os.DirFS("testdata/dir")); err != nil {
+		t.Fatal(err)
+	}
+	records, err = csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	fs.WalkDir(os.DirFS("testdata/dir"), ".", func(string, fs.DirEntry, error) error {
+		n++
+		return nil
+	})
+	if len(records) != n {
+		t.Errorf("DirFS: got %d records, expected %d", len(records), n)
+	}
+}
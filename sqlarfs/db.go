@@ -0,0 +1,14 @@
+package sqlarfs
+
+import "database/sql"
+
+// DB returns the database handle given to [New], to run ad-hoc queries on the
+// sqlar table (ex: custom reports) without keeping a separate reference.
+//
+// The FS caches the metadata of directories (and of files with [WithReadOnlyGuarantee]):
+// modifying the sqlar table through the handle may leave stale entries in the caches,
+// and breaks the promise of [WithReadOnlyGuarantee].
+func (ar *arfs) DB() *sql.DB {
+	db, _ := ar.db.(*sql.DB) // nil in a transaction (see WalkSnapshot), which is never exposed
+	return db
+}
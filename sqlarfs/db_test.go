@@ -0,0 +1,19 @@
+package sqlarfs_test
+
+import (
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestDB(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	ar := newFS(db, sqlarfs.WithReadOnlyGuarantee())
+	if ar.DB() != db {
+		t.Errorf("got %p, expected %p", ar.DB(), db)
+	}
+	var n int
+	if err := ar.DB().QueryRow(`SELECT COUNT(*) FROM sqlar`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+}
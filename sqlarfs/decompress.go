@@ -0,0 +1,289 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"sync"
+)
+
+// ErrDecompressBomb is returned (wrapped in an [*io/fs.PathError]) when the decompressed
+// content of a file exceeds the limits set with [WithMaxDecompressRatio] or [WithMaxDecompressBytes].
+var ErrDecompressBomb = errors.New("sqlarfs: decompressed size exceeds limit")
+
+// ErrChanged is returned (wrapped in an [*io/fs.PathError]) when the content of a file is read
+// after the file has been replaced in the archive since it was opened. See [WithReadOnlyGuarantee].
+var ErrChanged = errors.New("sqlarfs: file changed since opened")
+
+// ErrCorrupt is returned (wrapped in an [*io/fs.PathError]) when the content of a file is inconsistent
+// with its metadata.
+var ErrCorrupt = errors.New("sqlarfs: corrupt file data")
+
+// WithMaxDecompressRatio is an [Option] for [New] that limits the size of the decompressed
+// content of a file to ratio times the size of its compressed data.
+//
+// This protects against resource exhaustion when serving untrusted archives.
+func WithMaxDecompressRatio(ratio float64) Option {
+	if !(ratio > 0) {
+		panic(fmt.Errorf("sqlarfs.WithMaxDecompressRatio: invalid ratio"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.maxDecompressRatio = ratio
+	})
+}
+
+// WithMaxDecompressBytes is an [Option] for [New] that limits the size of the decompressed
+// content of a file to n bytes.
+//
+// This protects against resource exhaustion when serving untrusted archives.
+func WithMaxDecompressBytes(n int64) Option {
+	if n <= 0 {
+		panic(fmt.Errorf("sqlarfs.WithMaxDecompressBytes: invalid limit"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.maxDecompressBytes = n
+	})
+}
+
+// decompressLimit returns the maximum number of bytes allowed to be produced by
+// decompressing compressedLen bytes, or -1 if there is no limit.
+func (ar *arfs) decompressLimit(compressedLen int) int64 {
+	limit := int64(-1)
+	if ar.maxDecompressBytes > 0 {
+		limit = ar.maxDecompressBytes
+	}
+	if ar.maxDecompressRatio > 0 {
+		l := ar.maxDecompressRatio * float64(compressedLen)
+		if l < math.MaxInt64 && (limit < 0 || int64(l) < limit) {
+			limit = int64(l)
+		}
+	}
+	return limit
+}
+
+// openContent queries the data of the regular file name, whose metadata is info,
+// and returns a reader of its content.
+func (ar *arfs) openContent(name string, info *fileinfo) (io.ReadCloser, error) {
+	if ar.sparseReads {
+		if r, err := ar.openSparse(name, info); r != nil || err != nil {
+			return r, err
+		}
+	}
+	data, sum, err := ar.queryData(name, info)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ar.contentReader(name, data, info.sz, sum)
+	if err != nil {
+		return nil, err
+	}
+	return ar.transformReader(name, r)
+}
+
+// queryData queries the 'data' column of the regular file name and, if enabled
+// (see [WithVerifyHash]), the expected hash of its content.
+//
+// info is the metadata of the file when it was opened. Unless the archive is promised
+// not to change (see [WithReadOnlyGuarantee]), [ErrChanged] is returned if the 'mtime'
+// or 'sz' column of the row differs, as the file has been replaced since.
+func (ar *arfs) queryData(name string, info *fileinfo) (data []byte, sum []byte, err error) {
+	return ar.queryDataContext(context.Background(), name, info)
+}
+
+// queryDataContext is like queryData, but the query is bound to ctx.
+func (ar *arfs) queryDataContext(ctx context.Context, name string, info *fileinfo) (data []byte, sum []byte, err error) {
+	hashCol, err := ar.hashColumn()
+	if err != nil {
+		return nil, nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	var mtime, sz int64
+	err = ar.db.QueryRowContext(ctx, ``+
+		`SELECT data,`+hashCol+`,mtime,sz`+
+		` FROM `+ar.table()+
+		` WHERE name=?`+ar.collate()+
+		` AND `+ar.modeFilterReg(),
+		name,
+	).Scan(&data, &sum, &mtime, &sz)
+	switch err {
+	case nil:
+		if !ar.readOnly && (mtime != info.mtime || sz != info.sz) {
+			return nil, nil, &fs.PathError{Op: "read", Path: name, Err: ErrChanged}
+		}
+		data, err = ar.decodeData(name, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data, sum, nil
+	case sql.ErrNoRows:
+		return nil, nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	default:
+		return nil, nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+}
+
+// contentReader returns a reader of the content of the file name from the value of
+// its 'data' and 'sz' columns and, if not nil, the expected hash of the content (see [WithVerifyHash]).
+func (ar *arfs) contentReader(name string, data []byte, sz int64, sum []byte) (io.ReadCloser, error) {
+	r, err := ar.dataReader(name, data, sz)
+	if err != nil {
+		return nil, err
+	}
+	if sum != nil {
+		r = &hashReader{r: r, h: sha256.New(), sum: sum, path: name}
+	}
+	return r, nil
+}
+
+// dataReader returns a reader of the content of the file name from the value of
+// its 'data' column and of its 'sz' column.
+//
+// As defined by the [sqlar format], the content is stored compressed if and only if the size
+// of the data is lower than sz. If the size of the data is equal to sz, data is the content as is.
+// A producer that would store compressed data of exactly sz bytes would violate the format.
+// If the size of the data is greater than sz, [ErrCorrupt] is returned.
+//
+// [sqlar format]: https://sqlite.org/sqlar.html
+func (ar *arfs) dataReader(name string, data []byte, sz int64) (io.ReadCloser, error) {
+	switch {
+	case int64(len(data)) == sz:
+		return io.NopCloser(bytes.NewReader(data)), nil
+	case int64(len(data)) > sz:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: ErrCorrupt}
+	}
+	var r io.ReadCloser
+	var err error
+	if ar.sqliteExtCompat {
+		r, err = newZlibReader(bytes.NewReader(data))
+	} else {
+		r, err = ar.newDecompressReader(bytes.NewReader(data), data)
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	if ar.lenientRead {
+		r = &lenientReader{r: r, path: name}
+	}
+	if limit := ar.decompressLimit(len(data)); limit >= 0 {
+		r = &limitReader{r: r, n: limit, path: name}
+	}
+	return r, nil
+}
+
+// decompressor is a compression format identified by the magic bytes at the start of compressed data.
+type decompressor struct {
+	name      string
+	magic     string
+	newReader func(io.Reader) (io.ReadCloser, error)
+	method    Method
+	check     func(data []byte) bool // Optional check of the header, after the magic
+}
+
+// matches returns true if data starts with the header of the format.
+func (d *decompressor) matches(data []byte) bool {
+	return len(data) >= len(d.magic) && string(data[:len(d.magic)]) == d.magic &&
+		(d.check == nil || d.check(data))
+}
+
+// isBzip2Header returns true if data starts with a full bzip2 stream header: "BZh",
+// the block size ('1' to '9') and the magic of the first block (or of the end of an
+// empty stream). "B" is also a valid start of a raw DEFLATE stream, so the magic
+// "BZh" alone is not enough.
+func isBzip2Header(data []byte) bool {
+	if len(data) < 10 || data[3] < '1' || data[3] > '9' {
+		return false
+	}
+	block := string(data[4:10])
+	return block == "\x31\x41\x59\x26\x53\x59" || block == "\x17\x72\x45\x38\x50\x90"
+}
+
+var decompressors = struct {
+	mu   sync.RWMutex
+	list []decompressor
+}{
+	list: []decompressor{
+		{"bzip2", "BZh", func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		}, MethodBzip2, isBzip2Header},
+		{"gzip", gzipMagic, newGzipReader, MethodUnknown, nil},
+	},
+}
+
+// RegisterDecompressor registers a decompressor for compressed data that starts with magic.
+// This allows to read archives produced by non-standard tools.
+//
+// Compressed data that doesn't match any registered magic is decompressed with [compress/flate].
+// bzip2 (magic "BZh", followed by the rest of the stream header) and gzip (magic "\x1f\x8b")
+// are registered by default. Decompressors are only used for reading. They are ignored with
+// [WithSQLiteExtCompat], for the zlib data of the sqlar extension of SQLite.
+//
+// RegisterDecompressor is usually called from the init function of a package providing
+// a decompressor.
+func RegisterDecompressor(name string, magic string, newReader func(io.Reader) (io.ReadCloser, error)) {
+	if magic == "" || newReader == nil {
+		panic(fmt.Errorf("sqlarfs.RegisterDecompressor: invalid arguments"))
+	}
+	decompressors.mu.Lock()
+	defer decompressors.mu.Unlock()
+	decompressors.list = append(decompressors.list, decompressor{name, magic, newReader, MethodUnknown, nil})
+}
+
+// newDecompressReader returns a reader that decompresses r.
+// The decompressor is chosen from the magic bytes at the start of data (the content of r).
+func (ar *arfs) newDecompressReader(r io.Reader, data []byte) (io.ReadCloser, error) {
+	decompressors.mu.RLock()
+	defer decompressors.mu.RUnlock()
+	for _, d := range decompressors.list {
+		if d.matches(data) {
+			return d.newReader(r)
+		}
+	}
+	return ar.flateReader(r), nil
+}
+
+// compressionMethod returns the method of compressed data, identified by the magic bytes at its start.
+func compressionMethod(data []byte) Method {
+	decompressors.mu.RLock()
+	defer decompressors.mu.RUnlock()
+	for _, d := range decompressors.list {
+		if d.matches(data) {
+			return d.method
+		}
+	}
+	return MethodDeflate
+}
+
+// limitReader aborts reading with [ErrDecompressBomb] as soon as more than n bytes are produced.
+type limitReader struct {
+	r    io.ReadCloser
+	n    int64 // Remaining bytes allowed
+	path string
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, &fs.PathError{Op: "read", Path: l.path, Err: ErrDecompressBomb}
+	}
+	// Read at most one byte more than allowed to detect the overflow
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		n = int(l.n)
+		l.n = -1
+		return n, &fs.PathError{Op: "read", Path: l.path, Err: ErrDecompressBomb}
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+func (l *limitReader) Close() error {
+	return l.r.Close()
+}
@@ -0,0 +1,187 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// deflate compresses data with [compress/flate].
+func deflate(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressBomb(t *testing.T) {
+	content := make([]byte, 1<<20)
+	data := deflate(t, content)
+	t.Logf("compressed: %d bytes, ratio: %.0f", len(data), float64(len(content))/float64(len(data)))
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "bomb", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data})
+
+	for _, tc := range []struct {
+		name string
+		opts []sqlarfs.Option
+		err  error
+	}{
+		{"NoLimit", nil, nil},
+		{"MaxBytes", []sqlarfs.Option{sqlarfs.WithMaxDecompressBytes(1 << 16)}, sqlarfs.ErrDecompressBomb},
+		{"MaxBytesOK", []sqlarfs.Option{sqlarfs.WithMaxDecompressBytes(1 << 20)}, nil},
+		{"MaxRatio", []sqlarfs.Option{sqlarfs.WithMaxDecompressRatio(10)}, sqlarfs.ErrDecompressBomb},
+		{"MaxRatioOK", []sqlarfs.Option{sqlarfs.WithMaxDecompressRatio(10000)}, nil},
+		{"Both", []sqlarfs.Option{sqlarfs.WithMaxDecompressRatio(10000), sqlarfs.WithMaxDecompressBytes(1000)}, sqlarfs.ErrDecompressBomb},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ar := newFS(db, tc.opts...)
+			f, err := ar.Open("bomb")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			n, err := io.Copy(io.Discard, f)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, expected %v", err, tc.err)
+			}
+			var pathErr *fs.PathError
+			if tc.err != nil && !errors.As(err, &pathErr) {
+				t.Errorf("%T is not *fs.PathError", err)
+			}
+			if tc.err == nil && n != int64(len(content)) {
+				t.Errorf("got %d bytes, expected %d", n, len(content))
+			}
+			t.Logf("%d bytes read", n)
+		})
+	}
+}
+
+func TestDataSize(t *testing.T) {
+	content := bytes.Repeat([]byte("sqlar "), 100)
+	compressed := deflate(t, content)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "compressed", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed},
+		entry{name: "corrupt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)) - 1, data: content},
+	)
+	ar := newFS(db)
+
+	for _, name := range []string{"stored", "compressed"} {
+		b, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(b, content) {
+			t.Errorf("%s: unexpected content %q", name, b)
+		}
+	}
+
+	_, err := fs.ReadFile(ar, "corrupt")
+	if !errors.Is(err, sqlarfs.ErrCorrupt) {
+		t.Errorf("corrupt: got %v, expected ErrCorrupt", err)
+	}
+}
+
+func TestDecompressBzip2(t *testing.T) {
+	content := bytes.Repeat([]byte("bzip2 "), 100)
+	// python3 -c 'import bz2; print(bz2.compress(b"bzip2 "*100))'
+	data := []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\x3e\x21\x03\x56\x00\x00\x95\x99\x80\x40\x00\x10\x00\x10\x20\x40\x10\x20\x00\x30\xc0\x02\x95\x0c\x9c\x22\xc2\x2e\x91\x69\x16\x91\x69\x17\xc5\xdc\x91\x4e\x14\x24\x0f\x88\x40\xd5\x80")
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "file.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data})
+	ar := newFS(db)
+
+	b, err := fs.ReadFile(ar, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, content) {
+		t.Errorf("unexpected content %q", b)
+	}
+}
+
+// TestDecompressFlateBZh checks that raw DEFLATE data that starts with "BZh", the magic
+// of bzip2, is not read as bzip2.
+func TestDecompressFlateBZh(t *testing.T) {
+	dict := bytes.Repeat([]byte("dictionary "), 3000)
+	// Fixed Huffman block: a copy of 11 bytes from the dictionary at distance 8193+, then "!"
+	data := []byte("BZh\xa0\x08\x18\x00")
+	content := []byte("ry dictiona!")
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "file.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data})
+	ar := newFS(db, sqlarfs.WithFlateDictionary(dict))
+
+	b, err := fs.ReadFile(ar, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, content) {
+		t.Errorf("unexpected content %q", b)
+	}
+	if _, method, err := ar.CopyRawTo("file.txt", io.Discard); err != nil || method != sqlarfs.MethodDeflate {
+		t.Errorf("CopyRawTo: got %v, %v", method, err)
+	}
+}
+
+func TestDecompressGzip(t *testing.T) {
+	content := bytes.Repeat([]byte("gzip "), 100)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// The trailer has the CRC-32 then the size of the content
+	badCRC := bytes.Clone(data)
+	badCRC[len(badCRC)-8] ^= 0xff
+	badSize := bytes.Clone(data)
+	badSize[len(badSize)-4] ^= 0xff
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "file.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data},
+		entry{name: "bad-crc.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: badCRC},
+		entry{name: "bad-size.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: badSize},
+		entry{name: "truncated.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data[:len(data)-6]},
+	)
+	ar := newFS(db)
+
+	b, err := fs.ReadFile(ar, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, content) {
+		t.Errorf("unexpected content %q", b)
+	}
+
+	for _, name := range []string{"bad-crc.txt", "bad-size.txt", "truncated.txt"} {
+		if _, err := fs.ReadFile(ar, name); !errors.Is(err, sqlarfs.ErrCorrupt) {
+			t.Errorf("%s: got %v, expected ErrCorrupt", name, err)
+		}
+	}
+}
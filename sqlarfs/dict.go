@@ -0,0 +1,19 @@
+package sqlarfs
+
+// WithFlateDictionary is an [Option] for [New] that sets the preset dictionary used to
+// decompress DEFLATE data (see [compress/flate.NewReaderDict]).
+//
+// Some producers improve the compression of many similar small files by compressing them
+// with a dictionary shared by the whole archive. The contract with such a producer is:
+// the data of every compressed file is a raw DEFLATE stream written with
+// [compress/flate.NewWriterDict] (or zlib's deflateSetDictionary with raw deflate)
+// with exactly the same dictionary. Without the dictionary, such data decompresses to
+// garbage or fails with a decompression error.
+//
+// Data compressed with a decompressor registered with [RegisterDecompressor] is not affected.
+func WithFlateDictionary(dict []byte) Option {
+	dict = append([]byte(nil), dict...)
+	return optionFunc(func(ar *arfs) {
+		ar.flateDict = dict
+	})
+}
@@ -0,0 +1,71 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// deflateDict compresses data with [compress/flate] and a preset dictionary.
+func deflateDict(tb testing.TB, data, dict []byte) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriterDict(&buf, flate.BestCompression, dict)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFlateDictionary(t *testing.T) {
+	dict := []byte(`{"type":"translation","lang":"","messages":{"hello":"","goodbye":""}}`)
+
+	db := createDB(t, sqlarSchema)
+	contents := make(map[string][]byte)
+	for _, lang := range []string{"en", "fr", "de"} {
+		content := []byte(fmt.Sprintf(`{"type":"translation","lang":%q,"messages":{"hello":"hello-%[1]s","goodbye":"goodbye-%[1]s"}}`, lang))
+		name := lang + ".json"
+		contents[name] = content
+		data := deflateDict(t, content, dict)
+		if len(data) >= len(content) {
+			t.Fatalf("%s: not compressed", name)
+		}
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: data})
+	}
+
+	ar := sqlarfs.New(db, sqlarfs.WithFlateDictionary(dict))
+	for name, content := range contents {
+		b, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(b, content) {
+			t.Errorf("%s: got %q, expected %q", name, b, content)
+		}
+		// Decompressors from the pool are reset with the dictionary
+		b, err = fs.ReadFile(ar, name)
+		if err != nil || !bytes.Equal(b, content) {
+			t.Errorf("%s: second read: got %q, %v", name, b, err)
+		}
+	}
+
+	// Without the dictionary
+	ar = sqlarfs.New(db)
+	for name, content := range contents {
+		if b, err := fs.ReadFile(ar, name); err == nil && bytes.Equal(b, content) {
+			t.Errorf("%s: decompression without the dictionary succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// DirEntries returns an iterator (an iter.Seq2[fs.DirEntry, error], usable with range-over-func
+// starting with Go 1.23) over the entries of directory name.
+//
+// Unlike ReadDir, entries are streamed from a single query, so a huge directory
+// can be processed without loading all its entries in memory. Stopping the
+// iteration releases the query. On error, the iteration yields a nil entry
+// with the error, then stops.
+//
+// Entries are not in lexical order: an entry sorts as if its name had a '/' suffix.
+func (ar *arfs) DirEntries(name string) func(yield func(fs.DirEntry, error) bool) {
+	name = ar.cleanPath(name)
+	return func(yield func(fs.DirEntry, error) bool) {
+		if err := ar.dirEntries(name, yield); err != nil {
+			yield(nil, &fs.PathError{Op: "readdir", Path: name, Err: err})
+		}
+	}
+}
+
+func (ar *arfs) dirEntries(name string, yield func(fs.DirEntry, error) bool) error {
+	if !fs.ValidPath(name) {
+		return fs.ErrInvalid
+	}
+	var prefix string
+	if name == "." {
+		if _, err := ar.statRoot(); err != nil {
+			return err
+		}
+	} else {
+		fi, err := ar.stat(name)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return fs.ErrInvalid
+		}
+		if !ar.canRead(fi.mode) {
+			return fs.ErrPermission
+		}
+		prefix = name + "/"
+	}
+
+	// Sorting on name||'/' groups an explicit directory row ("a" => "a/") with
+	// the rows of its content ("a/b" => "a/b/").
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+`,mtime,sz`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+sqlValidName+
+		` ORDER BY name||'/'`,
+		ar.escapeLike(prefix)+"_%",
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var lastDir string
+	for rows.Next() {
+		var path string
+		fi := ar.newFileinfo()
+		if err := rows.Scan(&path, &fi.mode, &fi.mtime, &fi.sz); err != nil {
+			return err
+		}
+		if !fs.ValidPath(path) { // sqlValidName can't check the UTF-8 encoding
+			continue
+		}
+		rest := path[len(prefix):]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			// Directory implied by the path of its content
+			if i == 0 || rest[:i] == lastDir {
+				continue
+			}
+			lastDir = rest[:i]
+			if ar.hidden(prefix+lastDir, dirMode) {
+				continue
+			}
+			fi = ar.dirInfo.store(prefix+lastDir, &fileinfo{name: lastDir, mode: dirMode, loc: ar.location})
+		} else {
+			if !isDirMode(fi.mode) && !isRegMode(fi.mode) { // Skip files with broken mode (see sqlModeFilter)
+				continue
+			}
+			fi.name = rest
+			// Hide a directory implied by the paths of following entries: either it is
+			// a duplicate, or a file with the same name wins (see ReadDir)
+			lastDir = rest
+			if ar.hidden(path, fi.mode) {
+				continue
+			}
+			if fi.IsDir() {
+				fi = ar.dirInfo.store(path, fi)
+			}
+		}
+		if !yield(fs.FileInfoToDirEntry(fi), nil) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
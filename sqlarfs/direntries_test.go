@@ -0,0 +1,68 @@
+//go:build go1.23
+
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestDirEntries(t *testing.T) {
+	db := openDB(t, "testdata/dir.sqlar")
+	// With a single connection, a leaked query would block the next one
+	db.SetMaxOpenConns(1)
+	ar := newFS(db, sqlarfs.PermOwner)
+
+	for _, dir := range []string{".", "subdir", "subdir/subdir2"} {
+		var names []string
+		for d, err := range ar.DirEntries(dir) {
+			if err != nil {
+				t.Fatalf("%s: %v", dir, err)
+			}
+			names = append(names, d.Name())
+		}
+		sort.Strings(names)
+
+		entries, err := ar.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(names) != len(entries) {
+			t.Fatalf("%s: got %q, expected %d entries", dir, names, len(entries))
+		}
+		for i, e := range entries {
+			if names[i] != e.Name() {
+				t.Errorf("%s: got %q, expected %q", dir, names[i], e.Name())
+			}
+		}
+	}
+
+	// Break early
+	n := 0
+	for _, err := range ar.DirEntries(".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d iterations", n)
+	}
+	// The query has been released
+	if _, err := ar.ReadDir("."); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range ar.DirEntries("a.txt") {
+		if !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("a.txt: got %v, expected fs.ErrInvalid", err)
+		}
+	}
+}
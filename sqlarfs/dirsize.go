@@ -0,0 +1,98 @@
+package sqlarfs
+
+import "io/fs"
+
+// DirSize returns the total size (sum of the 'sz' column) of the regular files
+// below directory name, recursively, using a single aggregate query.
+//
+// Files that are not readable under the permission mask are not counted.
+// Permissions of intermediate subdirectories are not checked.
+// Hidden files (see [WithEntryFilter]) are not counted.
+//
+// Returns [fs.ErrNotExist] if name is not a directory.
+func (ar *arfs) DirSize(name string) (int64, error) {
+	name = ar.cleanPath(name)
+	if !fs.ValidPath(name) {
+		return 0, &fs.PathError{Op: "dirsize", Path: name, Err: fs.ErrInvalid}
+	}
+	var prefix string
+	if name == "." {
+		if _, err := ar.statRoot(); err != nil {
+			return 0, &fs.PathError{Op: "dirsize", Path: name, Err: err}
+		}
+	} else {
+		fi, err := ar.stat(name)
+		if err != nil {
+			return 0, &fs.PathError{Op: "dirsize", Path: name, Err: err}
+		}
+		if !fi.IsDir() {
+			return 0, &fs.PathError{Op: "dirsize", Path: name, Err: fs.ErrNotExist}
+		}
+		if !ar.canRead(fi.mode) {
+			return 0, &fs.PathError{Op: "dirsize", Path: name, Err: fs.ErrPermission}
+		}
+		prefix = name + "/"
+	}
+
+	if ar.keep != nil {
+		size, err := ar.dirSizeFiltered(prefix)
+		if err != nil {
+			return 0, &fs.PathError{Op: "dirsize", Path: name, Err: err}
+		}
+		return size, nil
+	}
+
+	var size int64
+	err := ar.db.QueryRow(``+
+		`SELECT COALESCE(SUM(sz),0)`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+ar.modeFilterReg()+
+		` AND `+sqlValidName+
+		` AND (mode&?)<>0`, // Readable files only
+		ar.escapeLike(prefix)+"_%",
+		0444&uint32(ar.permMask),
+	).Scan(&size)
+	if err != nil {
+		return 0, &fs.PathError{Op: "dirsize", Path: name, Err: err}
+	}
+	return size, nil
+}
+
+// dirSizeFiltered is DirSize with the sum computed in Go, to skip the entries hidden by
+// the filter set with [WithEntryFilter].
+func (ar *arfs) dirSizeFiltered(prefix string) (int64, error) {
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+`,sz`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+ar.modeFilterReg()+
+		` AND `+sqlValidName+
+		` AND (mode&?)<>0`, // Readable files only
+		ar.escapeLike(prefix)+"_%",
+		0444&uint32(ar.permMask),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var size int64
+	for rows.Next() {
+		var (
+			name string
+			mode uint32
+			sz   int64
+		)
+		if err := rows.Scan(&name, &mode, &sz); err != nil {
+			return 0, err
+		}
+		if !ar.hiddenPath(name, mode) {
+			size += sz
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	return size, rows.Close()
+}
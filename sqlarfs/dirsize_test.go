@@ -0,0 +1,37 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestDirSize(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar", sqlarfs.PermOwner)
+
+	for _, tc := range []struct {
+		dir  string
+		size int64
+	}{
+		{".", 12},
+		{"subdir", 8},
+		{"subdir/subdir2", 4},
+	} {
+		size, err := ar.DirSize(tc.dir)
+		if err != nil {
+			t.Errorf("DirSize(%q): %v", tc.dir, err)
+			continue
+		}
+		if size != tc.size {
+			t.Errorf("DirSize(%q): got %d, expected %d", tc.dir, size, tc.size)
+		}
+	}
+
+	for _, name := range []string{"a.txt", "missing", "subdir/c.txt"} {
+		if _, err := ar.DirSize(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("DirSize(%q): got %v, expected fs.ErrNotExist", name, err)
+		}
+	}
+}
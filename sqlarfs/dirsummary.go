@@ -0,0 +1,57 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"path"
+)
+
+// DirSummary is the summary of the content of a directory reported by DirSummaries.
+type DirSummary struct {
+	Files     int   // Number of regular files directly in the directory
+	Subdirs   int   // Number of directories directly in the directory
+	TotalSize int64 // Total size of the regular files below the directory, recursively
+}
+
+// DirSummaries returns the summaries of directory root (root included) and of all the directories
+// below it, indexed by path, using a single query, to display rich folder listings.
+//
+// Permissions are enforced like with Tree: the content of directories that can't be read
+// is not counted (their summary is empty), and files that are not readable under the
+// permission mask are not counted in TotalSize, like with DirSize.
+func (ar *arfs) DirSummaries(root string) (map[string]DirSummary, error) {
+	root = ar.cleanPath(root)
+	if !fs.ValidPath(root) {
+		return nil, &fs.PathError{Op: "readdir", Path: root, Err: fs.ErrInvalid}
+	}
+	summaries := map[string]DirSummary{root: {}}
+	err := ar.walkTree(root, func(p string, fi *fileinfo) error {
+		dir := path.Dir(p)
+		s := summaries[dir]
+		if fi.IsDir() {
+			s.Subdirs++
+			if _, found := summaries[p]; !found {
+				summaries[p] = DirSummary{} // Even if empty
+			}
+		} else {
+			s.Files++
+		}
+		summaries[dir] = s
+		if fi.IsDir() || !ar.canRead(fi.mode) {
+			return nil
+		}
+		// Add the size to all the ancestors up to root
+		for {
+			s := summaries[dir]
+			s.TotalSize += fi.sz
+			summaries[dir] = s
+			if dir == root {
+				return nil
+			}
+			dir = path.Dir(dir)
+		}
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: root, Err: err}
+	}
+	return summaries, nil
+}
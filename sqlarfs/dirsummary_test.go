@@ -0,0 +1,58 @@
+package sqlarfs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestDirSummaries(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+	summaries, err := ar.DirSummaries(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]sqlarfs.DirSummary{
+		".":              {Files: 2, Subdirs: 1, TotalSize: 12},
+		"subdir":         {Files: 2, Subdirs: 1, TotalSize: 8},
+		"subdir/subdir2": {Files: 2, TotalSize: 4},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("got %+v, expected %+v", summaries, expected)
+	}
+	for p, s := range summaries {
+		size, err := ar.DirSize(p)
+		if err != nil || size != s.TotalSize {
+			t.Errorf("%s: DirSize: got %d, %v, expected %d", p, size, err, s.TotalSize)
+		}
+	}
+
+	summaries, err = ar.DirSummaries("subdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(expected, ".")
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("subdir: got %+v, expected %+v", summaries, expected)
+	}
+
+	// The content of unreadable directories is not counted
+	summaries, err = openFS(t, "testdata/perms.sqlar", sqlarfs.PermOthers).DirSummaries(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]sqlarfs.DirSummary{
+		".":      {Subdirs: 3},
+		"user":   {},
+		"group":  {},
+		"others": {Files: 1},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("perms: got %+v, expected %+v", summaries, expected)
+	}
+
+	if _, err := ar.DirSummaries("a.txt"); err == nil {
+		t.Error("a.txt: error expected")
+	}
+}
@@ -0,0 +1,80 @@
+package sqlarfs
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+)
+
+// DataEncoding is the encoding of the 'data' column of the sqlar table. See [WithDataEncoding].
+type DataEncoding int
+
+const (
+	Raw    DataEncoding = iota // BLOB, as defined by the sqlar format
+	Base64                     // TEXT with standard base64 encoding (RFC 4648)
+	Hex                        // TEXT with hexadecimal encoding
+)
+
+// String implements interface [fmt.Stringer].
+func (enc DataEncoding) String() string {
+	switch enc {
+	case Raw:
+		return "raw"
+	case Base64:
+		return "base64"
+	case Hex:
+		return "hex"
+	default:
+		return fmt.Sprintf("DataEncoding(%d)", int(enc))
+	}
+}
+
+// WithDataEncoding is an [Option] for [New] to read archives produced by non-standard tools
+// that store the data of files as text (base64 or hexadecimal) instead of a BLOB.
+//
+// The data is decoded before decompression, so the 'sz' column must be the size of the content,
+// as in standard archives. Data that can't be decoded is reported as [ErrCorrupt].
+func WithDataEncoding(enc DataEncoding) Option {
+	switch enc {
+	case Raw, Base64, Hex:
+	default:
+		panic(fmt.Errorf("sqlarfs.WithDataEncoding: invalid encoding"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.dataEncoding = enc
+	})
+}
+
+// decodeData decodes the value of the 'data' column of file name.
+func (ar *arfs) decodeData(name string, data []byte) ([]byte, error) {
+	var (
+		n   int
+		err error
+	)
+	switch ar.dataEncoding {
+	case Base64:
+		buf := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		n, err = base64.StdEncoding.Decode(buf, data)
+		data = buf[:n]
+	case Hex:
+		n, err = hex.Decode(data, data) // Decode in place
+		data = data[:n]
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fmt.Errorf("%w: %s: %v", ErrCorrupt, ar.dataEncoding, err)}
+	}
+	return data, nil
+}
+
+// dataLengthExpr returns the SQL expression of the length of the decoded 'data' column.
+func (ar *arfs) dataLengthExpr() string {
+	switch ar.dataEncoding {
+	case Base64:
+		return `(length(data)*3/4-(data LIKE '%=')-(data LIKE '%=='))`
+	case Hex:
+		return `(length(data)/2)`
+	default:
+		return `length(data)`
+	}
+}
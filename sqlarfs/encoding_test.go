@@ -0,0 +1,90 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestDataEncoding(t *testing.T) {
+	content := bytes.Repeat([]byte("Hello world\n"), 100)
+	compressed := deflate(t, content)
+
+	for _, tc := range []struct {
+		enc    sqlarfs.DataEncoding
+		encode func([]byte) string
+	}{
+		{sqlarfs.Base64, base64.StdEncoding.EncodeToString},
+		{sqlarfs.Hex, hex.EncodeToString},
+	} {
+		t.Run(tc.enc.String(), func(t *testing.T) {
+			db := createDB(t, `CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data TEXT)`)
+			for _, e := range []struct {
+				name string
+				data string
+			}{
+				{"stored.txt", tc.encode(content)},
+				{"compressed.txt", tc.encode(compressed)},
+				{"bad.txt", "@@@@"},
+			} {
+				_, err := db.Exec(`INSERT INTO sqlar(name,mode,mtime,sz,data) VALUES(?,?,0,?,?)`, e.name, syscall.S_IFREG|0644, len(content), e.data)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ar := newFS(db, sqlarfs.WithDataEncoding(tc.enc))
+			for _, name := range []string{"stored.txt", "compressed.txt"} {
+				b, err := fs.ReadFile(ar, name)
+				if err != nil {
+					t.Errorf("%s: %v", name, err)
+				} else if !bytes.Equal(b, content) {
+					t.Errorf("%s: content mismatch", name)
+				}
+			}
+			if _, err := fs.ReadFile(ar, "bad.txt"); !errors.Is(err, sqlarfs.ErrCorrupt) {
+				t.Errorf("bad.txt: got %v, expected ErrCorrupt", err)
+			}
+
+			files, err := ar.ReadFiles([]string{"compressed.txt"})
+			if err != nil {
+				t.Error(err)
+			} else if !bytes.Equal(files["compressed.txt"], content) {
+				t.Error("ReadFiles: content mismatch")
+			}
+
+			for name, stored := range map[string][]byte{"stored.txt": content, "compressed.txt": compressed} {
+				f, h, err := ar.OpenWithInfo(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Close()
+				if h.StoredSize != int64(len(stored)) || h.Compressed != (name == "compressed.txt") {
+					t.Errorf("OpenWithInfo(%q): got %+v", name, h)
+				}
+			}
+
+			if stored, _, err := ar.EstimateCompression("compressed.txt"); err != nil || stored != int64(len(compressed)) {
+				t.Errorf("EstimateCompression: got %d, %v", stored, err)
+			}
+
+			// Without the option, the text is read as is
+			if _, err := fs.ReadFile(newFS(db), "compressed.txt"); err == nil {
+				t.Error("Raw: error expected")
+			}
+		})
+	}
+
+	// Raw BLOB read as base64
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "blob", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed})
+	if _, err := fs.ReadFile(newFS(db, sqlarfs.WithDataEncoding(sqlarfs.Base64)), "blob"); !errors.Is(err, sqlarfs.ErrCorrupt) {
+		t.Errorf("blob: got %v, expected ErrCorrupt", err)
+	}
+}
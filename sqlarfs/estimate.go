@@ -0,0 +1,85 @@
+package sqlarfs
+
+import (
+	"compress/flate"
+	"context"
+	"database/sql"
+	"io"
+	"io/fs"
+)
+
+// estimateSampleSize is the maximum number of bytes of content compressed by EstimateCompression.
+const estimateSampleSize = 1 << 20
+
+// EstimateCompression reports the size of the stored data of regular file name and
+// an estimate of the smallest size achievable by storing it compressed with DEFLATE at
+// the best compression level, to decide if recompressing the file (see [Optimize]) is worth it.
+//
+// The estimate is computed by compressing at most the first MiB of the content, so the cost
+// is bounded for large files. As with the [sqlar format], the estimate is never more than
+// the size of the content, as incompressible content is stored as is.
+//
+// [sqlar format]: https://sqlite.org/sqlar.html
+func (ar *arfs) EstimateCompression(name string) (currentStored, bestEstimate int64, err error) {
+	f, err := ar.OpenContext(context.Background(), name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	file, ok := f.(*file)
+	if !ok {
+		return 0, 0, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	err = ar.db.QueryRow(``+
+		`SELECT COALESCE(`+ar.dataLengthExpr()+`,0)`+
+		` FROM `+ar.table()+
+		` WHERE name=?`+ar.collate()+
+		` AND `+ar.modeFilterReg(),
+		file.path,
+	).Scan(&currentStored)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return 0, 0, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	default:
+		return 0, 0, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	sz := file.info.sz
+	if sz == 0 {
+		return currentStored, 0, nil
+	}
+	sample, err := readAll(io.LimitReader(f, estimateSampleSize), make([]byte, 0, bufCap(min(sz, estimateSampleSize))))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(sample) == 0 {
+		return 0, 0, &fs.PathError{Op: "read", Path: name, Err: ErrCorrupt}
+	}
+
+	var w countWriter
+	fw, err := flate.NewWriter(&w, flate.BestCompression)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fw.Write(sample); err != nil {
+		return 0, 0, err
+	}
+	if err := fw.Close(); err != nil {
+		return 0, 0, err
+	}
+	bestEstimate = int64(float64(w) * float64(sz) / float64(len(sample)))
+	if bestEstimate >= sz {
+		bestEstimate = sz
+	}
+	return currentStored, bestEstimate, nil
+}
+
+// countWriter counts the bytes written.
+type countWriter int64
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,75 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"math/rand"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestEstimateCompression(t *testing.T) {
+	text := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1000))
+	// Huffman-only compression doesn't use back-references
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.HuffmanOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(text)
+	fw.Close()
+	poor := buf.Bytes()
+
+	random := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(random)
+	large := bytes.Repeat(text, 100) // More than the sample
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "poor.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(text)), data: poor},
+		entry{name: "best.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(text)), data: deflate(t, text)},
+		entry{name: "random.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(random)), data: random},
+		entry{name: "large.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(large)), data: large},
+		entry{name: "empty.txt", mode: syscall.S_IFREG | 0644},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := newFS(db)
+
+	stored, best, err := ar.EstimateCompression("poor.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("poor.txt: stored %d, best %d", stored, best)
+	if stored != int64(len(poor)) || best <= 0 || best > stored/10 {
+		t.Errorf("poor.txt: got %d, %d", stored, best)
+	}
+
+	stored, best, err = ar.EstimateCompression("best.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best > stored*11/10 || best < stored*9/10 {
+		t.Errorf("best.txt: got %d, %d", stored, best)
+	}
+
+	stored, best, err = ar.EstimateCompression("random.bin")
+	if err != nil || stored != int64(len(random)) || best != stored {
+		t.Errorf("random.bin: got %d, %d, %v", stored, best, err)
+	}
+
+	stored, best, err = ar.EstimateCompression("large.txt")
+	t.Logf("large.txt: stored %d, best %d", stored, best)
+	if err != nil || stored != int64(len(large)) || best <= 0 || best > stored/10 {
+		t.Errorf("large.txt: got %d, %d, %v", stored, best, err)
+	}
+
+	if stored, best, err = ar.EstimateCompression("empty.txt"); err != nil || stored != 0 || best != 0 {
+		t.Errorf("empty.txt: got %d, %d, %v", stored, best, err)
+	}
+	for _, name := range []string{".", "dir", "missing.txt"} {
+		if _, _, err := ar.EstimateCompression(name); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
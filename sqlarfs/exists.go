@@ -0,0 +1,169 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Exists reports which of names exist in the archive, either as entries of the
+// sqlar table or as directories implied by the paths of other entries.
+// The keys of the result are the names as given (see [WithTrailingSlash]).
+//
+// This is cheaper than a Stat for each name: names are checked by chunks with
+// a single query per chunk. Entries with a broken mode are reported as missing, and so
+// are the entries below a regular file, as with Stat. Permissions are not checked.
+// Hidden entries (see [WithEntryFilter]) and their content are reported as missing.
+func (ar *arfs) Exists(names []string) (map[string]bool, error) {
+	found := make(map[string]bool, len(names)) // By cleaned path
+	dirs := map[string]bool{".": true}
+	var query []string
+	for _, name := range names {
+		clean := ar.cleanPath(name)
+		if !fs.ValidPath(clean) {
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+		}
+		if _, seen := found[clean]; seen {
+			continue
+		}
+		found[clean] = false
+		if clean == "." {
+			if _, err := ar.statRoot(); err != nil {
+				return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+			}
+			found[clean] = true
+			continue
+		}
+		query = append(query, clean)
+	}
+	if err := ar.checkSchema(); err != nil {
+		return nil, err
+	}
+
+	for len(query) > 0 {
+		chunk := query
+		if len(chunk) > readFilesChunk {
+			chunk = chunk[:readFilesChunk]
+		}
+		query = query[len(chunk):]
+		if err := ar.exists(chunk, found, dirs); err != nil {
+			return nil, err
+		}
+	}
+	if err := ar.hideUnderFiles(found); err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		clean := ar.cleanPath(name)
+		exists[name] = found[clean] && (dirs[clean] || !ar.dirOnly(name)) // "a.txt/" doesn't exist
+	}
+	return exists, nil
+}
+
+// hideUnderFiles reports as missing the names of found that are below a regular file
+// ("a.txt/b" if "a.txt" is a file), as Stat can't reach them.
+func (ar *arfs) hideUnderFiles(found map[string]bool) error {
+	var parents []string
+	seen := make(map[string]bool)
+	for name, ok := range found {
+		for dir := path.Dir(name); ok && dir != "." && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			parents = append(parents, dir)
+		}
+	}
+	files := make(map[string]bool)
+	for len(parents) > 0 {
+		chunk := parents
+		if len(chunk) > readFilesChunk {
+			chunk = chunk[:readFilesChunk]
+		}
+		parents = parents[len(chunk):]
+		if err := ar.regularFiles(chunk, files); err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	for name, ok := range found {
+		for dir := path.Dir(name); ok && dir != "."; dir = path.Dir(dir) {
+			if files[dir] {
+				found[name] = false
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// regularFiles sets files[name] for the names that are regular files.
+func (ar *arfs) regularFiles(names []string, files map[string]bool) error {
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := ar.db.Query(``+
+		`WITH q(n) AS (VALUES (?)`+strings.Repeat(",(?)", len(names)-1)+`)`+
+		` SELECT n`+
+		` FROM q`+
+		` WHERE EXISTS(SELECT 1 FROM `+ar.table()+` WHERE name=n`+ar.collate()+` AND `+ar.modeFilterReg()+`)`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		files[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// exists sets exists[name] for the names that exist, and dirs[name] for those that are directories.
+func (ar *arfs) exists(names []string, exists, dirs map[string]bool) error {
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	// The range name>n||'/' AND name<n||'0' ('0' follows '/') uses the index to
+	// find the content of a directory.
+	rows, err := ar.db.Query(``+
+		`WITH q(n) AS (VALUES (?)`+strings.Repeat(",(?)", len(names)-1)+`)`+
+		` SELECT n,(SELECT `+ar.modeExpr()+` FROM `+ar.table()+` WHERE name=n`+ar.collate()+` AND `+ar.modeFilter()+`)`+
+		` FROM q`+
+		` WHERE EXISTS(SELECT 1 FROM `+ar.table()+` WHERE name=n`+ar.collate()+` AND `+ar.modeFilter()+`)`+
+		` OR EXISTS(SELECT 1 FROM `+ar.table()+` WHERE name>n||'/' AND name<n||'0' AND `+sqlValidName+`)`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var mode sql.NullInt64
+		if err := rows.Scan(&name, &mode); err != nil {
+			return err
+		}
+		m := dirMode // Directory implied by the paths of other entries
+		if mode.Valid {
+			m = uint32(mode.Int64)
+		}
+		exists[name] = !ar.hiddenPath(name, m)
+		dirs[name] = isDirMode(m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,80 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestExists(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+	expected := map[string]bool{
+		".":                    true,
+		"a.txt":                true,
+		"subdir":               true,
+		"subdir/subdir2/f.txt": true,
+		"subdir/subdir2/g.txt": false,
+		"missing":              false,
+		"subdir/missing/c.txt": false,
+		"subdi":                false,
+		"A.TXT":                false,
+	}
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	names = append(names, "a.txt") // Duplicate
+
+	got, err := ar.Exists(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(expected) {
+		t.Errorf("got %d results, expected %d", len(got), len(expected))
+	}
+	for name, exists := range expected {
+		if got[name] != exists {
+			t.Errorf("%s: got %t, expected %t", name, got[name], exists)
+		}
+	}
+
+	// Implied directories
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "x/y/z.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("z")})
+	ar = newFS(db)
+	if got, err := ar.Exists([]string{"x", "x/y", "x/y/z.txt", "x/z"}); err != nil || !got["x"] || !got["x/y"] || !got["x/y/z.txt"] || got["x/z"] {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	// Entries below a file are not reachable
+	insertEntries(t, db,
+		entry{name: "file", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("f")},
+		entry{name: "file/sub/g.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("g")},
+	)
+	if got, err := ar.Exists([]string{"file", "file/sub", "file/sub/g.txt", "x/y"}); err != nil || !got["file"] || got["file/sub"] || got["file/sub/g.txt"] || !got["x/y"] {
+		t.Errorf("below a file: got %v, %v", got, err)
+	}
+
+	// The content of a hidden directory is hidden too, as with Stat
+	insertEntries(t, db, entry{name: ".secret/key.pem", mode: syscall.S_IFREG | 0600, sz: 1, data: []byte("k")})
+	hidden := newFS(db, sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != ".secret" }))
+	if got, err := hidden.Exists([]string{".secret", ".secret/key.pem", "x/y/z.txt"}); err != nil || got[".secret"] || got[".secret/key.pem"] || !got["x/y/z.txt"] {
+		t.Errorf("hidden: got %v, %v", got, err)
+	}
+	if _, err := fs.Stat(hidden, ".secret/key.pem"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("hidden: Stat got %v, expected ErrNotExist", err)
+	}
+
+	// The results are keyed by the names as given
+	ar = newFS(db, sqlarfs.WithTrailingSlash())
+	if got, err := ar.Exists([]string{"x/", "x/y/"}); err != nil || len(got) != 2 || !got["x/"] || !got["x/y/"] {
+		t.Errorf("trailing slash: got %v, %v", got, err)
+	}
+
+	if _, err := ar.Exists([]string{"/a"}); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("got %v, expected ErrInvalid", err)
+	}
+}
@@ -0,0 +1,41 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"path"
+)
+
+// Extensions returns the number of regular files per extension (as returned by [path.Ext],
+// such as ".txt", or "" for files without extension), using a single query.
+// This is useful to build filters by type without walking the archive.
+//
+// Files that are not readable under the permission mask, or hidden (see [WithEntryFilter]),
+// are not counted. Permissions of directories are not checked.
+func (ar *arfs) Extensions() (map[string]int64, error) {
+	rows, err := ar.db.Query(`` +
+		`SELECT name,` + ar.modeExpr() +
+		` FROM ` + ar.table() +
+		` WHERE ` + ar.modeFilterReg() +
+		` AND ` + sqlValidName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exts := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var mode uint32
+		if err := rows.Scan(&name, &mode); err != nil {
+			return nil, err
+		}
+		if !fs.ValidPath(name) || !ar.canRead(mode) || ar.hiddenPath(name, mode) {
+			continue
+		}
+		exts[path.Ext(name)]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return exts, rows.Close()
+}
@@ -0,0 +1,45 @@
+package sqlarfs_test
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestExtensions(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	for _, name := range []string{"a.txt", "b.txt", "dir/c.txt", "dir/d.go", "e.tar.gz", "Makefile", "dir.d/f"} {
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")})
+	}
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "private.txt", mode: syscall.S_IFREG | 0600, sz: 1, data: []byte("x")},
+	)
+
+	for _, tc := range []struct {
+		opts     []sqlarfs.Option
+		expected map[string]int64
+	}{
+		{nil, map[string]int64{".txt": 4, ".go": 1, ".gz": 1, "": 2}},
+		{[]sqlarfs.Option{sqlarfs.PermOthers}, map[string]int64{".txt": 3, ".go": 1, ".gz": 1, "": 2}},
+		{
+			[]sqlarfs.Option{sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != "dir/d.go" })},
+			map[string]int64{".txt": 4, ".gz": 1, "": 2},
+		},
+		{
+			// The content of a hidden directory is hidden too
+			[]sqlarfs.Option{sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != "dir" })},
+			map[string]int64{".txt": 3, ".gz": 1, "": 2},
+		},
+	} {
+		exts, err := newFS(db, tc.opts...).Extensions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(exts, tc.expected) {
+			t.Errorf("got %v, expected %v", exts, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package sqlarfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ExtractOption is an option for Extract.
+//
+// Available options: [WithExtractVerify], [WithExtractWorkers].
+type ExtractOption func(*extractOptions)
+
+type extractOptions struct {
+	verify  bool // See WithExtractVerify
+	workers int  // See WithExtractWorkers
+}
+
+// WithExtractVerify is an [ExtractOption] that verifies the content of each file against
+// the hash stored in the archive (see [WithVerifyHash]) while it is written to disk.
+//
+// On mismatch, the extraction is aborted with an [*io/fs.PathError] (that wraps
+// [ErrHashMismatch]) for the file that failed, and the partial file is removed.
+// Verification is available only if ar is an [FS] returned by [New].
+func WithExtractVerify() ExtractOption {
+	return func(o *extractOptions) {
+		o.verify = true
+	}
+}
+
+// WithExtractWorkers is an [ExtractOption] that decompresses and writes n files concurrently,
+// to use multiple cores for decompression.
+//
+// Each worker reads one file at a time, so at most n connections of the pool
+// of the database are used for reading. Directories are still created in order
+// by the walk of the archive, before the files they contain are dispatched to the workers.
+func WithExtractWorkers(n int) ExtractOption {
+	if n <= 0 {
+		panic(fmt.Errorf("sqlarfs.WithExtractWorkers: invalid number of workers"))
+	}
+	return func(o *extractOptions) {
+		o.workers = n
+	}
+}
+
+// Extract writes the content of ar to directory dir, which is created if needed.
+// The permissions and modification times of files and directories are preserved
+// (except for dir itself). Existing files are overwritten.
+func Extract(ar fs.FS, dir string, opts ...ExtractOption) error {
+	return ExtractContext(context.Background(), ar, dir, opts...)
+}
+
+// ExtractContext is like Extract, but the extraction stops when ctx is done.
+func ExtractContext(ctx context.Context, ar fs.FS, dir string, opts ...ExtractOption) error {
+	o := extractOptions{workers: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if a, ok := ar.(*arfs); ok && o.verify && !a.verifyHash {
+		ar = a.withVerifyHash()
+	}
+
+	// The first error cancels the extraction
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		firstErr error
+		errOnce  sync.Once
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	files := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range files {
+				if ctx.Err() != nil {
+					continue // Drain
+				}
+				if err := extractFile(ar, path, filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	var dirs []string
+	err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		p := filepath.Join(dir, filepath.FromSlash(path))
+		if path == "." {
+			return os.MkdirAll(p, 0o777)
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			// Permissions are set once the content is written
+			return os.MkdirAll(p, 0o700)
+		}
+		select {
+		case files <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(files)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Deepest directories first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := fs.Stat(ar, dirs[i])
+		if err != nil {
+			return err
+		}
+		p := filepath.Join(dir, filepath.FromSlash(dirs[i]))
+		if err := os.Chmod(p, info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(p, info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile writes the content of file name of ar to file p.
+func extractFile(ar fs.FS, name string, p string) error {
+	f, err := ar.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	w, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, f); err != nil {
+		w.Close()
+		os.Remove(p)
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) {
+			// Report which file failed
+			err = &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	// Fix the permissions of an existing file
+	if err = os.Chmod(p, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(p, info.ModTime(), info.ModTime())
+}
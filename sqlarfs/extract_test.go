@@ -0,0 +1,112 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestExtractVerify(t *testing.T) {
+	db := createDB(t, `CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB, sha256 BLOB)`)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/good.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("good")},
+		entry{name: "dir/tampered.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("evil")},
+	)
+	good := sha256.Sum256([]byte("good"))
+	orig := sha256.Sum256([]byte("orig"))
+	for name, sum := range map[string][]byte{"dir/good.txt": good[:], "dir/tampered.txt": orig[:]} {
+		if _, err := db.Exec(`UPDATE sqlar SET sha256=? WHERE name=?`, sum, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ar := sqlarfs.New(db)
+
+	// Without verification
+	if err := sqlarfs.Extract(ar, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	err := sqlarfs.Extract(ar, dir, sqlarfs.WithExtractVerify())
+	var pathErr *os.PathError
+	if !errors.Is(err, sqlarfs.ErrHashMismatch) || !errors.As(err, &pathErr) || pathErr.Path != "dir/tampered.txt" {
+		t.Fatalf("got %v, expected ErrHashMismatch for dir/tampered.txt", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "dir", "good.txt")); err != nil || string(b) != "good" {
+		t.Errorf("good.txt: got %q, %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dir", "tampered.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("tampered.txt: partial file not removed: %v", err)
+	}
+}
+
+// createFilesDB creates an archive with n compressed files of size bytes in a few directories.
+func createFilesDB(tb testing.TB, n int, size int) *sql.DB {
+	tb.Helper()
+	db := createDB(tb, sqlarSchema)
+	for i := 0; i < n; i++ {
+		content := bytes.Repeat([]byte(fmt.Sprintf("file %d ", i)), size/8)
+		insertEntries(tb, db, entry{
+			name: fmt.Sprintf("d%d/f%d.txt", i%4, i),
+			mode: syscall.S_IFREG | 0644,
+			sz:   int64(len(content)),
+			data: deflate(tb, content),
+		})
+	}
+	return db
+}
+
+func TestExtractWorkers(t *testing.T) {
+	db := createFilesDB(t, 50, 1000)
+	ar := sqlarfs.New(db)
+	dir := t.TempDir()
+	if err := sqlarfs.Extract(ar, dir, sqlarfs.WithExtractWorkers(4)); err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := sqlarfs.VerifyAgainstDir(ar, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches: %v", mismatches)
+	}
+
+	// The first error stops the extraction
+	insertEntries(t, db, entry{name: "d0/broken.txt", mode: syscall.S_IFREG | 0644, sz: 100, data: []byte("garbage")})
+	err = sqlarfs.Extract(ar, t.TempDir(), sqlarfs.WithExtractWorkers(4))
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "d0/broken.txt" {
+		t.Errorf("got %v, expected an error for d0/broken.txt", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sqlarfs.ExtractContext(ctx, ar, t.TempDir(), sqlarfs.WithExtractWorkers(2)); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: got %v", err)
+	}
+}
+
+func BenchmarkExtractWorkers(b *testing.B) {
+	ar := sqlarfs.New(createFilesDB(b, 64, 1<<18), sqlarfs.WithReadOnlyGuarantee())
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := sqlarfs.Extract(ar, b.TempDir(), sqlarfs.WithExtractWorkers(n)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
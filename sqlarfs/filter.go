@@ -0,0 +1,36 @@
+package sqlarfs
+
+// WithEntryFilter is an [Option] for [New] that hides the entries for which keep returns false
+// (for example anything under ".secret/"): they behave as if they were absent from the archive
+// ([io/fs.ErrNotExist]) and do not appear in listings.
+//
+// keep receives the path of the entry and its Unix mode (see [FileHeader]).
+// The filter is enforced in Go after the queries, so it may be any function, but
+// it must be cheap. The content of a hidden directory is hidden too, even if keep
+// would accept the paths of its entries. This also applies to the aggregates
+// (ex: DirSize), which are computed in Go instead of SQL when a filter is set.
+func WithEntryFilter(keep func(path string, mode uint32) bool) Option {
+	return optionFunc(func(ar *arfs) {
+		ar.keep = keep
+	})
+}
+
+// hidden returns true if the entry with path and mode is hidden by the filter set with [WithEntryFilter].
+func (ar *arfs) hidden(path string, mode uint32) bool {
+	return ar.keep != nil && !ar.keep(path, mode&^permClassMask)
+}
+
+// hiddenPath is like hidden, but also returns true if a parent directory of path is hidden.
+// This is for the methods that scan entries without traversing the tree from the root:
+// the parent directories are checked with the mode of implied directories.
+func (ar *arfs) hiddenPath(path string, mode uint32) bool {
+	if ar.keep == nil {
+		return false
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && ar.hidden(path[:i], dirMode) {
+			return true
+		}
+	}
+	return ar.hidden(path, mode)
+}
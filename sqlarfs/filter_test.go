@@ -0,0 +1,111 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestEntryFilter(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: ".secret", mode: syscall.S_IFDIR | 0755},
+		entry{name: ".secret/key", mode: syscall.S_IFREG | 0600, sz: 1, data: []byte("k")},
+		entry{name: ".secret/sub/key2", mode: syscall.S_IFREG | 0600, sz: 1, data: []byte("k")},
+		entry{name: "implied/.secret/x", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")},
+		entry{name: "implied/y", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("y")},
+	)
+	ar := newFS(db, sqlarfs.WithEntryFilter(func(path string, mode uint32) bool {
+		return path != ".secret" && !strings.HasSuffix(path, "/.secret")
+	}))
+
+	var walked []string
+	err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(walked, " "); got != ". a.txt implied implied/y" {
+		t.Errorf("WalkDir: got %q", got)
+	}
+
+	for _, name := range []string{".secret", ".secret/key", ".secret/sub", ".secret/sub/key2", "implied/.secret", "implied/.secret/x"} {
+		if _, err := fs.Stat(ar, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q): got %v, expected ErrNotExist", name, err)
+		}
+		if _, err := ar.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%q): got %v, expected ErrNotExist", name, err)
+		}
+	}
+
+	tree, err := ar.Tree(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || len(tree["."]) != 2 || len(tree["implied"]) != 1 {
+		t.Errorf("Tree: got %v", tree)
+	}
+
+	ar.DirEntries(".")(func(e fs.DirEntry, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Name() == ".secret" {
+			t.Error("DirEntries: .secret listed")
+		}
+		return true
+	})
+
+	if _, err := ar.ReadFiles([]string{"a.txt", ".secret/key"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFiles: got %v, expected ErrNotExist", err)
+	}
+
+	if err := fstest.TestFS(ar, "a.txt", "implied/y"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEntryFilterAggregates(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "top/a.txt", mode: syscall.S_IFREG | 0644, sz: 10, data: []byte("0123456789")},
+		entry{name: "top/.secret/key.pem", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("key")},
+		entry{name: "top/.secret/broken", mode: syscall.S_IFREG | 0644, sz: 100, data: []byte("xx")},
+		entry{name: ".secret", mode: syscall.S_IFDIR | 0755},
+		entry{name: ".secret/b.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("01234")},
+	)
+	keep := sqlarfs.WithEntryFilter(func(path string, mode uint32) bool {
+		return path != ".secret" && !strings.HasSuffix(path, "/.secret")
+	})
+
+	for _, tc := range []struct {
+		ar     extFS
+		size   int64
+		prefix string
+		broken int
+	}{
+		{newFS(db), 118, "", 1},
+		{newFS(db, keep), 10, "top", 0},
+	} {
+		if size, err := tc.ar.DirSize("."); err != nil || size != tc.size {
+			t.Errorf("DirSize: got %d, %v, expected %d", size, err, tc.size)
+		}
+		if prefix, err := tc.ar.CommonPrefix(); err != nil || prefix != tc.prefix {
+			t.Errorf("CommonPrefix: got %q, %v, expected %q", prefix, err, tc.prefix)
+		}
+		if broken, err := tc.ar.BrokenEntries(); err != nil || len(broken) != tc.broken {
+			t.Errorf("BrokenEntries: got %v, %v, expected %d entries", broken, err, tc.broken)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package sqlarfs
+
+import (
+	"fmt"
+)
+
+// Format is the framing of the compressed data of an archive. See CompressionFormat.
+type Format int
+
+const (
+	FormatUnknown Format = iota // No compressed entry, or unrecognized compression
+	FormatFlate                 // Raw DEFLATE stream (RFC 1951)
+	FormatZlib                  // zlib stream (RFC 1950), as produced by the sqlite3 command-line tool
+	FormatMixed                 // Several formats
+)
+
+// String implements interface [fmt.Stringer].
+func (f Format) String() string {
+	switch f {
+	case FormatUnknown:
+		return "unknown"
+	case FormatFlate:
+		return "flate"
+	case FormatZlib:
+		return "zlib"
+	case FormatMixed:
+		return "mixed"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// compressionFormatSamples is the number of compressed entries inspected by CompressionFormat.
+const compressionFormatSamples = 16
+
+// CompressionFormat reports the framing of the compressed data of the archive, to help
+// to diagnose archives that can't be read.
+//
+// A few compressed entries are sampled and only the headers of their data are inspected:
+// nothing is decompressed. Permissions are not checked.
+func (ar *arfs) CompressionFormat() (Format, error) {
+	if err := ar.checkSchema(); err != nil {
+		return FormatUnknown, err
+	}
+	// The headers (and the magic of other formats, see RegisterDecompressor) are in the first 10 bytes.
+	// Enough of the encoded data must be fetched to decode them.
+	headerLen := 10
+	switch ar.dataEncoding {
+	case Base64:
+		headerLen = 16
+	case Hex:
+		headerLen = 20
+	}
+	rows, err := ar.db.Query(``+
+		`SELECT name,substr(data,1,?)`+
+		` FROM `+ar.table()+
+		` WHERE `+ar.dataLengthExpr()+`<sz`+
+		` AND `+ar.modeFilterReg()+
+		` LIMIT ?`,
+		headerLen,
+		compressionFormatSamples,
+	)
+	if err != nil {
+		return FormatUnknown, err
+	}
+	defer rows.Close()
+
+	var flate, zlib, other bool
+	for rows.Next() {
+		var name string
+		var header []byte
+		if err := rows.Scan(&name, &header); err != nil {
+			return FormatUnknown, err
+		}
+		header, err := ar.decodeData(name, header)
+		switch {
+		case err != nil:
+			other = true
+		case isZlibHeader(header):
+			zlib = true
+		case compressionMethod(header) != MethodDeflate:
+			other = true
+		case len(header) > 0 && header[0]&6 != 6: // BTYPE of the first block is not reserved
+			flate = true
+		default:
+			other = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return FormatUnknown, err
+	}
+	if err := rows.Close(); err != nil {
+		return FormatUnknown, err
+	}
+
+	switch {
+	case flate && !zlib && !other:
+		return FormatFlate, nil
+	case zlib && !flate && !other:
+		return FormatZlib, nil
+	case flate && zlib || (flate || zlib) && other:
+		return FormatMixed, nil
+	default:
+		return FormatUnknown, nil
+	}
+}
+
+// isZlibHeader returns true if b starts with a zlib header (RFC 1950) for DEFLATE.
+func isZlibHeader(b []byte) bool {
+	return len(b) >= 2 &&
+		b[0]&0x0f == 8 && // CM: DEFLATE
+		b[0]>>4 <= 7 && // CINFO: window size
+		(uint16(b[0])<<8|uint16(b[1]))%31 == 0 // FCHECK
+}
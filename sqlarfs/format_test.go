@@ -0,0 +1,56 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// zlibCompress compresses data with [compress/zlib], like the sqlite3 command-line tool.
+func zlibCompress(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompressionFormat(t *testing.T) {
+	content := bytes.Repeat([]byte("format "), 100)
+	stored := entry{name: "stored.txt", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("abc")}
+	flate := entry{name: "flate.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: deflate(t, content)}
+	zlib := entry{name: "zlib.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: zlibCompress(t, content)}
+	// Only the magic matters: the data is not decompressed
+	bzip2 := entry{name: "bzip2.txt", mode: syscall.S_IFREG | 0644, sz: 600, data: []byte("BZh91AY&SY")}
+
+	for _, tc := range []struct {
+		entries  []entry
+		expected sqlarfs.Format
+	}{
+		{nil, sqlarfs.FormatUnknown},
+		{[]entry{stored}, sqlarfs.FormatUnknown},
+		{[]entry{stored, flate}, sqlarfs.FormatFlate},
+		{[]entry{stored, zlib}, sqlarfs.FormatZlib},
+		{[]entry{flate, zlib}, sqlarfs.FormatMixed},
+		{[]entry{bzip2}, sqlarfs.FormatUnknown},
+		{[]entry{zlib, bzip2}, sqlarfs.FormatMixed},
+	} {
+		db := createDB(t, sqlarSchema)
+		insertEntries(t, db, tc.entries...)
+		got, err := newFS(db).CompressionFormat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("%d entries: got %s, expected %s", len(tc.entries), got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// FreelistCount returns the number of unused pages in the database file of db
+// (see [PRAGMA freelist_count]). Unused pages are left by the deletion of data until the
+// database is vacuumed.
+//
+// [PRAGMA freelist_count]: https://sqlite.org/pragma.html#pragma_freelist_count
+func FreelistCount(db *sql.DB) (int64, error) {
+	var n int64
+	if err := db.QueryRow(`PRAGMA freelist_count`).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ShouldVacuum returns true if the fraction of unused pages of the database file of db is more
+// than threshold (between 0 and 1), so maintenance tools can decide when to run VACUUM.
+// An error is returned if threshold is invalid.
+func ShouldVacuum(db *sql.DB, threshold float64) (bool, error) {
+	if !(threshold >= 0 && threshold < 1) { // NaN included
+		return false, fmt.Errorf("sqlarfs.ShouldVacuum: invalid threshold %v", threshold)
+	}
+	var free, total int64
+	if err := db.QueryRow(`SELECT freelist_count,page_count FROM pragma_freelist_count(),pragma_page_count()`).Scan(&free, &total); err != nil {
+		return false, err
+	}
+	return total > 0 && float64(free) > threshold*float64(total), nil
+}
@@ -0,0 +1,53 @@
+package sqlarfs_test
+
+import (
+	"fmt"
+	"math"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestFreelist(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	for i := 0; i < 100; i++ {
+		data := make([]byte, 10000)
+		insertEntries(t, db, entry{name: fmt.Sprintf("file%d.bin", i), mode: syscall.S_IFREG | 0644, sz: int64(len(data)), data: data})
+	}
+
+	check := func(expectFree bool) {
+		t.Helper()
+		n, err := sqlarfs.FreelistCount(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("freelist: %d", n)
+		if (n > 0) != expectFree {
+			t.Errorf("got %d free pages", n)
+		}
+		vacuum, err := sqlarfs.ShouldVacuum(db, 0.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vacuum != expectFree {
+			t.Errorf("ShouldVacuum: got %t", vacuum)
+		}
+	}
+
+	check(false)
+	if _, err := db.Exec(`DELETE FROM sqlar WHERE name<>'file0.bin'`); err != nil {
+		t.Fatal(err)
+	}
+	check(true)
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		t.Fatal(err)
+	}
+	check(false)
+
+	for _, threshold := range []float64{-0.1, 1, math.NaN()} {
+		if _, err := sqlarfs.ShouldVacuum(db, threshold); err == nil {
+			t.Errorf("threshold %v: error expected", threshold)
+		}
+	}
+}
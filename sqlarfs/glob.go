@@ -0,0 +1,129 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// globLike translates a pattern (syntax of [path.Match]) into an SQL LIKE
+// pattern (using ar.escapeChar() as escape character).
+//
+// The LIKE pattern matches a superset of the names matched by the glob
+// pattern: '*' also matches '/', character classes match any character and
+// LIKE is case insensitive for ASCII letters. So the names returned by the
+// query must still be filtered with [path.Match].
+func (ar *arfs) globLike(pattern string) (string, error) {
+	// Validate the pattern
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", err
+	}
+
+	var like strings.Builder
+	literal := func(s string) {
+		like.WriteString(ar.escapeLike(s))
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			like.WriteByte('%')
+		case '?':
+			like.WriteByte('_')
+		case '[':
+			// Skip the character class
+			i++
+			if i < len(pattern) && pattern[i] == '^' {
+				i++
+			}
+			for n := 0; i < len(pattern); i++ {
+				if pattern[i] == ']' && n > 0 {
+					break
+				}
+				if pattern[i] == '\\' {
+					i++
+				}
+				n++
+			}
+			like.WriteByte('_')
+		case '\\':
+			i++
+			literal(pattern[i : i+1])
+		default:
+			j := i + 1
+			for j < len(pattern) && !strings.ContainsRune(`*?[\`, rune(pattern[j])) {
+				j++
+			}
+			literal(pattern[i:j])
+			i = j - 1
+		}
+	}
+	return like.String(), nil
+}
+
+// globRows queries the names of the entries (explicit rows of the sqlar table) matching
+// pattern, ordered by name. fn is called for each matching name until it returns false.
+func (ar *arfs) globRows(pattern string, fn func(name string) bool) error {
+	like, err := ar.globLike(pattern)
+	if err != nil {
+		return err
+	}
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+ar.modeFilter()+
+		` ORDER BY name`,
+		like,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var mode uint32
+		if err := rows.Scan(&name, &mode); err != nil {
+			return err
+		}
+		if !fs.ValidPath(name) || name == "." || ar.hidden(name, mode) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok && !fn(name) {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// OpenFirstMatch opens the first entry (in name order) matching pattern.
+// The syntax of pattern is the same as in [path.Match].
+//
+// Only the entries stored in the sqlar table are considered: directories
+// that are implied by the paths of files are not matched.
+//
+// The path of the entry is returned with the file.
+// [fs.ErrNotExist] is returned if no entry matches.
+func (ar *arfs) OpenFirstMatch(pattern string) (fs.File, string, error) {
+	var name string
+	err := ar.globRows(pattern, func(n string) bool {
+		name = n
+		return false
+	})
+	if err == nil && name == "" {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, "", &fs.PathError{Op: "open", Path: pattern, Err: err}
+	}
+	f, err := ar.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, name, nil
+}
@@ -0,0 +1,49 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenFirstMatch(t *testing.T) {
+	ar := openFS(t, "testdata/simple.sqlar", sqlarfs.PermOwner)
+
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		content string
+	}{
+		{"foo.*", "foo.txt", "Foo\n"},
+		{"*.txt", "bar.txt", "Bar\n"},
+		{"[fg]o?.txt", "foo.txt", "Foo\n"},
+	} {
+		f, name, err := ar.OpenFirstMatch(tc.pattern)
+		if err != nil {
+			t.Errorf("%q: %v", tc.pattern, err)
+			continue
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%q: %v", tc.pattern, err)
+		}
+		if name != tc.name || string(b) != tc.content {
+			t.Errorf("%q: got %q %q, expected %q %q", tc.pattern, name, b, tc.name, tc.content)
+		}
+	}
+
+	if _, _, err := ar.OpenFirstMatch("FOO.*"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FOO.*: got %v, expected fs.ErrNotExist", err)
+	}
+	if _, _, err := ar.OpenFirstMatch("baz.*"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("baz.*: got %v, expected fs.ErrNotExist", err)
+	}
+	if _, _, err := ar.OpenFirstMatch("[foo"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("[foo: got %v, expected path.ErrBadPattern", err)
+	}
+}
@@ -0,0 +1,134 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Glob implements interface [fs.GlobFS], with the same results as the generic
+// implementation of [fs.Glob] (which lists each directory matched by the pattern),
+// but the entries matching the pattern are selected by SQLite using a single query.
+// Permissions are enforced as with [fs.Glob]: the directories that are listed
+// must be readable, and their parents must be traversable.
+//
+// Patterns that are not valid paths (see [fs.ValidPath]) are handled by the generic implementation.
+func (ar *arfs) Glob(pattern string) ([]string, error) {
+	// Validate the pattern
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if !hasGlobMeta(pattern) {
+		if _, err := ar.Stat(pattern); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+	if !fs.ValidPath(pattern) {
+		return fs.Glob(struct{ fs.ReadDirFS }{ar}, pattern) // Hide method Glob
+	}
+
+	like, err := ar.globLike(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// Directories implied by the paths of their content also match
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+
+		` FROM `+ar.table()+
+		` WHERE (name LIKE ?1 ESCAPE '`+ar.escapeChar()+`'`+
+		` OR name LIKE ?1||'/%' ESCAPE '`+ar.escapeChar()+`')`+
+		` AND `+sqlValidName,
+		like,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := strings.Split(pattern, "/")
+	depth := len(components)
+	matches := make(map[string]uint32) // Mode of the matching entries
+	for rows.Next() {
+		var name string
+		var mode uint32
+		if err := rows.Scan(&name, &mode); err != nil {
+			return nil, err
+		}
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		// Truncate name to the depth of the pattern
+		parts := strings.SplitN(name, "/", depth+1)
+		if len(parts) < depth {
+			continue
+		}
+		entry := strings.Join(parts[:depth], "/")
+		if ok, _ := path.Match(pattern, entry); !ok {
+			continue
+		}
+		if entry != name {
+			mode = dirMode // Implied by the path of name
+		} else if !isDirMode(mode) && !isRegMode(mode) { // Skip files with broken mode (see sqlModeFilter)
+			continue
+		}
+		// A file wins over a directory, an explicit directory over an implied one (see ReadDir)
+		if prev, seen := matches[entry]; seen && (isRegMode(prev) || entry != name) {
+			continue
+		}
+		matches[entry] = mode
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// The generic implementation lists the parent directories of the components of the pattern
+	// starting from the first one with meta characters
+	first := 0
+	for first < depth-1 && !hasGlobMeta(components[first]) {
+		first++
+	}
+	listable := make(map[string]bool)
+	canList := func(dir string) bool {
+		ok, done := listable[dir]
+		if !done {
+			if dir == "." {
+				_, err := ar.statRoot()
+				ok = err == nil
+			} else {
+				fi, err := ar.stat(dir)
+				ok = err == nil && fi.IsDir() && ar.canRead(fi.mode)
+			}
+			listable[dir] = ok
+		}
+		return ok
+	}
+
+	var result []string
+	for entry, mode := range matches {
+		if ar.hidden(entry, mode) {
+			continue
+		}
+		ok := true
+		for dir, n := path.Dir(entry), depth-1; ok && n >= first; dir, n = path.Dir(dir), n-1 {
+			ok = canList(dir)
+		}
+		if ok {
+			result = append(result, entry)
+		}
+	}
+	// Sort like the generic implementation: by directory, then by name
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ReplaceAll(result[i], "/", "\x00") < strings.ReplaceAll(result[j], "/", "\x00")
+	})
+	return result, nil
+}
+
+// hasGlobMeta reports whether pattern contains any of the special characters of [path.Match].
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -0,0 +1,74 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestGlobFS(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "conf", mode: syscall.S_IFDIR | 0755},
+		entry{name: "conf/app.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "conf/APP.JSON", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "conf/dev/db.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "conf/prod/db.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "conf/prod/db.yaml", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("a:")},
+		entry{name: "conf.d/x.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "private", mode: syscall.S_IFDIR | 0700},
+		entry{name: "private/p.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "private/sub/q.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "locked", mode: syscall.S_IFDIR | 0744}, // Readable, not traversable by others
+		entry{name: "locked/sub", mode: syscall.S_IFDIR | 0755},
+		entry{name: "locked/sub/r.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "shadow", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("s")},
+		entry{name: "shadow/hidden.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "link.json", mode: syscall.S_IFLNK | 0777, sz: 6, data: []byte("a.json")},
+		entry{name: "odd[1]/*.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+		entry{name: "100%_/x.json", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("{}")},
+	)
+
+	patterns := []string{
+		"*.json", "*", "*/*", "*/*.json", "*/*/*.json", "conf/*.json", "conf/*/*.json", "conf/*/db.*",
+		"conf/prod/*", "c*/*", "conf?d/*", "[a-c]*", "[^a-c]*/*", "*/sub/*", "locked/sub/*", "private/*",
+		"private/*/*", "shadow/*", "shadow", "conf/app.json", "missing", "odd\\[1\\]/\\*.json", "odd*/*",
+		"100%_/*", "100*/*", "*/*/*/*", "./*", "/*", "conf/*/", "conf//*", "../*",
+	}
+	for _, opts := range [][]sqlarfs.Option{
+		{sqlarfs.PermAny},
+		{sqlarfs.PermOthers},
+		{sqlarfs.PermOwner, sqlarfs.WithEntryFilter(func(name string, mode uint32) bool { return path.Base(name) != "dev" })},
+	} {
+		ar := newFS(db, opts...)
+		for _, pattern := range patterns {
+			got, err := ar.Glob(pattern)
+			if err != nil {
+				t.Errorf("Glob(%q): %v", pattern, err)
+				continue
+			}
+			expected, _ := fs.Glob(struct{ fs.ReadDirFS }{ar}, pattern) // Generic implementation
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Glob(%q) with %v: got %q, expected %q", pattern, ar.Config().PermMask, got, expected)
+			}
+		}
+	}
+
+	got, err := fs.Glob(newFS(db), "*/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"conf/dev/db.json", "conf/prod/db.json", "locked/sub/r.json", "private/sub/q.json"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if _, err := newFS(db).Glob("conf/[a-"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("bad pattern: got %v, expected path.ErrBadPattern", err)
+	}
+}
@@ -0,0 +1,43 @@
+package sqlarfs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// gzipMagic is the magic of the gzip format (RFC 1952), used by some tools to frame
+// compressed data instead of raw DEFLATE. There is no ambiguity with raw DEFLATE data,
+// as 0x1f would start a block of the reserved type.
+//
+// Only reading is supported: gzip is never used to compress data.
+const gzipMagic = "\x1f\x8b"
+
+// newGzipReader returns a reader of the content of gzip data.
+// The CRC-32 and the size in the trailer of the data are checked at the end
+// of the content: [ErrCorrupt] is returned on mismatch.
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, gzipError(err)
+	}
+	return &gzipReader{zr}, nil
+}
+
+type gzipReader struct {
+	*gzip.Reader
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	return n, gzipError(err)
+}
+
+// gzipError wraps the errors of invalid gzip data with [ErrCorrupt].
+func gzipError(err error) error {
+	if errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: gzip: %w", ErrCorrupt, err)
+	}
+	return err
+}
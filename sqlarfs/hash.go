@@ -0,0 +1,74 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+)
+
+// ErrHashMismatch is returned (wrapped in an [*io/fs.PathError]) at the end of the read of a
+// file when its content doesn't match the hash stored in the archive. See [WithVerifyHash].
+var ErrHashMismatch = errors.New("sqlarfs: content hash mismatch")
+
+// hashColumnName is the name of the optional column of the sqlar table
+// that stores the SHA-256 hash of the content of files.
+const hashColumnName = "sha256"
+
+// WithVerifyHash is an [Option] for [New] that enables the verification of the content
+// of files against the SHA-256 hash stored in column 'sha256' (an extension of the
+// standard sqlar schema), to provide end-to-end integrity for untrusted archives.
+//
+// The hash is checked when the end of a file is reached. [ErrHashMismatch] is returned on mismatch.
+// If the sqlar table has no 'sha256' column, or if the column is NULL for a file,
+// no verification happens.
+func WithVerifyHash() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.verifyHash = true
+	})
+}
+
+// hashColumn returns the SQL expression to select the expected hash of the content of a file.
+func (ar *arfs) hashColumn() (string, error) {
+	if !ar.verifyHash {
+		return `NULL`, nil
+	}
+	columns, err := ar.columns()
+	if err != nil {
+		return "", err
+	}
+	if !columns[hashColumnName] {
+		return `NULL`, nil
+	}
+	return hashColumnName, nil
+}
+
+// hashReader computes the hash of the content read and compares it to sum at EOF.
+type hashReader struct {
+	r    io.ReadCloser
+	h    hash.Hash
+	sum  []byte
+	path string
+}
+
+func (hr *hashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(hr.h.Sum(nil), hr.sum) {
+		return n, &fs.PathError{Op: "read", Path: hr.path, Err: ErrHashMismatch}
+	}
+	return n, err
+}
+
+func (hr *hashReader) Close() error {
+	return hr.r.Close()
+}
+
+// withVerifyHash returns a copy of ar (with the same options but empty caches) with hash verification enabled.
+// The limits of concurrent reads and of bandwidth are shared with ar.
+func (ar *arfs) withVerifyHash() *arfs {
+	v := &arfs{db: ar.db, options: ar.options, caches: new(caches), reads: ar.reads, rate: ar.rate}
+	v.verifyHash = true
+	return v
+}
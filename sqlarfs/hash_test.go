@@ -0,0 +1,52 @@
+package sqlarfs_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestVerifyHash(t *testing.T) {
+	db := createDB(t, `CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB, sha256 BLOB)`)
+	insertEntries(t, db,
+		entry{name: "good.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("good")},
+		entry{name: "bad.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("evil")},
+		entry{name: "nohash.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("none")},
+	)
+	good := sha256.Sum256([]byte("good"))
+	bad := sha256.Sum256([]byte("bad!"))
+	for name, sum := range map[string][]byte{"good.txt": good[:], "bad.txt": bad[:]} {
+		if _, err := db.Exec(`UPDATE sqlar SET sha256=? WHERE name=?`, sum, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ar := newFS(db, sqlarfs.WithVerifyHash())
+	for _, name := range []string{"good.txt", "nohash.txt"} {
+		if _, err := fs.ReadFile(ar, name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := fs.ReadFile(ar, "bad.txt"); !errors.Is(err, sqlarfs.ErrHashMismatch) {
+		t.Errorf("bad.txt: got %v, expected ErrHashMismatch", err)
+	}
+	_, err := ar.ReadFiles([]string{"good.txt", "bad.txt"})
+	if !errors.Is(err, sqlarfs.ErrHashMismatch) {
+		t.Errorf("ReadFiles: got %v, expected ErrHashMismatch", err)
+	}
+
+	// Without the option
+	if _, err := fs.ReadFile(newFS(db), "bad.txt"); err != nil {
+		t.Errorf("bad.txt: %v", err)
+	}
+
+	// Without the column
+	ar = openFS(t, "testdata/simple.sqlar", sqlarfs.WithVerifyHash())
+	if _, err := fs.ReadFile(ar, "foo.txt"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,78 @@
+package sqlarfs
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+)
+
+// FileHeader describes how an entry is stored in the sqlar table.
+type FileHeader struct {
+	RowID      int64  // rowid in the sqlar table. 0 for directories implied by the paths of their content.
+	Mode       uint32 // Unix mode ('mode' column)
+	Size       int64  // Size of the content ('sz' column)
+	StoredSize int64  // Size of the stored data ('length(data)', once decoded: see WithDataEncoding)
+	Compressed bool   // The stored data is compressed (StoredSize < Size)
+}
+
+// OpenWithInfo is like Open, but also returns how the entry is stored.
+// The metadata of the file and the header are retrieved with a single query.
+//
+// This is useful to decide if the compressed data could be served as is to an HTTP client.
+func (ar *arfs) OpenWithInfo(name string) (fs.File, FileHeader, error) {
+	dirOnly := ar.dirOnly(name)
+	name = ar.cleanPath(name)
+	if name == "." {
+		f, err := ar.Open(name)
+		if err != nil {
+			return nil, FileHeader{}, err
+		}
+		return f, FileHeader{Mode: f.(*dir).info.mode}, nil
+	}
+	if !fs.ValidPath(name) {
+		return nil, FileHeader{}, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	filename, err := ar.traverseParent(name)
+	if err != nil {
+		return nil, FileHeader{}, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	var h FileHeader
+	info := fileinfo{name: filename, loc: ar.location}
+	err = ar.db.QueryRow(``+
+		`SELECT rowid,`+ar.modeExpr()+`,mtime,sz,COALESCE(`+ar.dataLengthExpr()+`,0)`+
+		` FROM `+ar.table()+
+		` WHERE name=?`+ar.collate()+
+		` AND `+ar.modeFilter()+ // Skip file with broken mode
+		` LIMIT 1`,
+		name,
+	).Scan(&h.RowID, &info.mode, &info.mtime, &info.sz, &h.StoredSize)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		// Directory implied by the paths of its content (or missing file)
+		f, err := ar.Open(name)
+		if err != nil {
+			return nil, FileHeader{}, err
+		}
+		return f, FileHeader{Mode: dirMode}, nil
+	default:
+		return nil, FileHeader{}, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if ar.hidden(name, info.mode) || dirOnly && !info.IsDir() {
+		return nil, FileHeader{}, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	h.Mode = info.mode &^ permClassMask
+	h.Size = info.sz
+	h.Compressed = h.StoredSize < h.Size
+
+	if info.IsDir() {
+		ar.dirInfo.store(name, &info)
+		return &dir{file: file{fs: ar, info: info, path: name}}, h, nil
+	}
+	f := &file{fs: ar, info: info, path: name}
+	if err := ar.acquireRead(context.Background(), f); err != nil {
+		return nil, FileHeader{}, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, h, nil
+}
@@ -0,0 +1,66 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenWithInfo(t *testing.T) {
+	content := bytes.Repeat([]byte("sqlar "), 100)
+	compressed := deflate(t, content)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/stored.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "dir/compressed.txt", mode: syscall.S_IFREG | 0600, sz: int64(len(content)), data: compressed},
+		entry{name: "implied/file.txt", mode: syscall.S_IFREG | 0644, sz: 0, data: []byte{}},
+	)
+	ar := newFS(db)
+
+	for _, tc := range []struct {
+		name   string
+		header sqlarfs.FileHeader
+	}{
+		{"dir", sqlarfs.FileHeader{RowID: 1, Mode: syscall.S_IFDIR | 0755}},
+		{"dir/stored.txt", sqlarfs.FileHeader{RowID: 2, Mode: syscall.S_IFREG | 0644, Size: int64(len(content)), StoredSize: int64(len(content))}},
+		{"dir/compressed.txt", sqlarfs.FileHeader{RowID: 3, Mode: syscall.S_IFREG | 0600, Size: int64(len(content)), StoredSize: int64(len(compressed)), Compressed: true}},
+		{"implied", sqlarfs.FileHeader{Mode: syscall.S_IFDIR | 0555}},
+	} {
+		f, h, err := ar.OpenWithInfo(tc.name)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if h != tc.header {
+			t.Errorf("%s: got %+v, expected %+v", tc.name, h, tc.header)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.IsDir() {
+			if _, ok := f.(fs.ReadDirFile); !ok {
+				t.Errorf("%s: fs.ReadDirFile expected", tc.name)
+			}
+		} else {
+			b, err := io.ReadAll(f)
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			} else if !bytes.Equal(b, content) {
+				t.Errorf("%s: unexpected content %q", tc.name, b)
+			}
+		}
+		f.Close()
+	}
+
+	if _, _, err := ar.OpenWithInfo("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing: got %v, expected fs.ErrNotExist", err)
+	}
+}
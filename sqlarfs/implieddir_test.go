@@ -0,0 +1,63 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestStatImpliedDir checks that the directories that have no entry of their own,
+// but are implied by the paths of their content, can be opened.
+func TestStatImpliedDir(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "dir/sub/a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")})
+	ar := sqlarfs.New(db)
+
+	for _, name := range []string{"dir", "dir/sub"} {
+		info, err := fs.Stat(ar, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !info.IsDir() {
+			t.Errorf("%s: got mode %v, expected a directory", name, info.Mode())
+		}
+	}
+	if _, err := fs.Stat(ar, "di"); err == nil {
+		t.Error("di: error expected")
+	}
+}
+
+// TestReadDirDeepImpliedDir checks that ReadDir lists the directories implied only by
+// entries two or more levels below them.
+func TestReadDirDeepImpliedDir(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "c/d/e/f", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("f")},
+	)
+	ar := sqlarfs.New(db)
+
+	for dir, expected := range map[string][]string{
+		".":     {"a.txt", "c"},
+		"c":     {"d"},
+		"c/d":   {"e"},
+		"c/d/e": {"f"},
+	} {
+		entries, err := ar.ReadDir(dir)
+		if err != nil {
+			t.Errorf("%s: %v", dir, err)
+			continue
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: got %q, expected %q", dir, got, expected)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sqlarfs
+
+import "io/fs"
+
+// Inaccessible returns the paths of the entries of the archive, sorted, that are
+// not accessible under the permission mask: regular files that can't be read, and
+// directories that can't be listed or traversed. This explains why [io/fs.WalkDir]
+// skips some parts of the archive under a restrictive mask.
+//
+// Only the mode of each entry is checked, using a single query: the content of an
+// inaccessible directory is not reported, unless it is itself inaccessible.
+// Hidden entries (see [WithEntryFilter]) are not reported.
+func (ar *arfs) Inaccessible() ([]string, error) {
+	rows, err := ar.db.Query(`` +
+		`SELECT name,` + ar.modeExpr() +
+		` FROM ` + ar.table() +
+		` WHERE ` + ar.modeFilter() +
+		` AND ` + sqlValidName +
+		` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		var mode uint32
+		if err := rows.Scan(&name, &mode); err != nil {
+			return nil, err
+		}
+		if !fs.ValidPath(name) || ar.hiddenPath(name, mode) {
+			continue
+		}
+		if !ar.canRead(mode) || (isDirMode(mode) && !ar.canTraverse(mode)) {
+			names = append(names, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return names, rows.Close()
+}
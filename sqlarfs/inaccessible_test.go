@@ -0,0 +1,46 @@
+package sqlarfs_test
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestInaccessible(t *testing.T) {
+	for _, tc := range []struct {
+		opts     []sqlarfs.Option
+		expected []string
+	}{
+		{[]sqlarfs.Option{sqlarfs.PermOthers}, []string{"group", "user"}},
+		{[]sqlarfs.Option{sqlarfs.PermOwner}, []string{"group", "others"}},
+		{[]sqlarfs.Option{sqlarfs.PermAny}, nil},
+		{
+			[]sqlarfs.Option{sqlarfs.PermOthers, sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != "user" })},
+			[]string{"group"},
+		},
+	} {
+		names, err := openFS(t, "testdata/perms.sqlar", tc.opts...).Inaccessible()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("got %q, expected %q", names, tc.expected)
+		}
+	}
+
+	// The content of a hidden directory is hidden too
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/private.txt", mode: syscall.S_IFREG | 0600, sz: 1, data: []byte("x")},
+	)
+	if names, err := newFS(db, sqlarfs.PermOthers).Inaccessible(); err != nil || !reflect.DeepEqual(names, []string{"dir/private.txt"}) {
+		t.Errorf("got %q, %v", names, err)
+	}
+	hideDir := sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != "dir" })
+	if names, err := newFS(db, sqlarfs.PermOthers, hideDir).Inaccessible(); err != nil || names != nil {
+		t.Errorf("hidden dir: got %q, %v", names, err)
+	}
+}
@@ -0,0 +1,34 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Info returns the application_id and user_version stamped in the header of an SQLite database.
+//
+// Producers of archives may use those values to identify a variant or a version
+// of their archive format. See [PRAGMA application_id] and [PRAGMA user_version].
+//
+// [PRAGMA application_id]: https://sqlite.org/pragma.html#pragma_application_id
+// [PRAGMA user_version]: https://sqlite.org/pragma.html#pragma_user_version
+func Info(db *sql.DB) (appID int32, userVersion int32, err error) {
+	if err = db.QueryRow(`PRAGMA application_id`).Scan(&appID); err != nil {
+		return 0, 0, err
+	}
+	if err = db.QueryRow(`PRAGMA user_version`).Scan(&userVersion); err != nil {
+		return 0, 0, err
+	}
+	return appID, userVersion, nil
+}
+
+// RowCount returns the number of rows of table (usually "sqlar") of an SQLite database.
+//
+// Unlike the listing of an [FS], the count includes the rows that are hidden
+// because of a broken mode or name. A discrepancy between the count and the
+// number of visible entries may reveal a corrupt archive (see BrokenEntries).
+func RowCount(db *sql.DB, table string) (int64, error) {
+	var n int64
+	err := db.QueryRow(`SELECT count(*) FROM "` + strings.ReplaceAll(table, `"`, `""`) + `"`).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,63 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestInfo(t *testing.T) {
+	appID, userVersion, err := sqlarfs.Info(openDB(t, "testdata/simple.sqlar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appID != 0 || userVersion != 0 {
+		t.Errorf("simple.sqlar: got %d %d, expected 0 0", appID, userVersion)
+	}
+
+	db := createDB(t, sqlarSchema,
+		`PRAGMA application_id = 0x53514152`, // "SQAR"
+		`PRAGMA user_version = -3`,
+	)
+	appID, userVersion, err = sqlarfs.Info(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appID != 0x53514152 || userVersion != -3 {
+		t.Errorf("got %#x %d, expected %#x %d", appID, userVersion, 0x53514152, -3)
+	}
+}
+
+func TestRowCount(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "b.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("b")},
+		entry{name: "badmode", mode: 0644, sz: 3, data: []byte("abc")},
+	)
+
+	n, err := sqlarfs.RowCount(db, "sqlar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var visible int64
+	err = fs.WalkDir(sqlarfs.New(db), ".", func(path string, d fs.DirEntry, err error) error {
+		if path != "." {
+			visible++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 || visible != 3 {
+		t.Errorf("got %d rows, %d visible entries, expected 4, 3", n, visible)
+	}
+
+	if _, err := sqlarfs.RowCount(db, "missing"); err == nil {
+		t.Error("missing table: error expected")
+	}
+}
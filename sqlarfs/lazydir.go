@@ -0,0 +1,162 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// WithLazyReadDir is an [Option] for [New] that makes the ReadDir method of the handles of
+// directories (see [fs.ReadDirFile]) fetch the entries incrementally instead of loading
+// the whole directory on the first call: each ReadDir(n) call runs one query that
+// streams the rows following the last row read (a keyset cursor on the name),
+// using a statement prepared on the first call and released by Close.
+//
+// This bounds the memory used to iterate over huge directories with ReadDir(n).
+// Entries are returned in the order of the names of the rows: the directories implied by
+// the paths of their content are not in lexical order ("a.txt" comes before "a" if there
+// is no row for "a"). Note that [fs.WalkDir] and [fs.ReadDir] use the ReadDir method of
+// the [FS], that is not affected and loads the whole directory.
+func WithLazyReadDir() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.lazyReadDir = true
+	})
+}
+
+// lazyDir is the state of the incremental listing of a directory. See WithLazyReadDir.
+type lazyDir struct {
+	stmt   *sql.Stmt
+	prefix string // "" or with a trailing '/'
+	hi     string // Upper bound of the names below prefix (prefix with '0' instead of the trailing '/')
+	cursor string // Name of the last row read
+	done   bool
+
+	// Names (relative to prefix) of the entries that hide the directory implied by
+	// the paths of the following rows (see ReadDir). As rows are sorted by name, the rows
+	// between "a" and "a/" have "a" as prefix, so this is a stack.
+	explicit []string
+}
+
+// readDirLazy implements ReadDir with WithLazyReadDir.
+func (d *dir) readDirLazy(n int) ([]fs.DirEntry, error) {
+	ar := d.file.fs
+	if d.lazy == nil {
+		l := &lazyDir{}
+		query := `` +
+			`SELECT name,` + ar.modeExpr() + `,mtime,sz` +
+			` FROM ` + ar.table() +
+			` WHERE name>?` // Use the index to start after the cursor
+		if d.file.path != "." {
+			if !ar.canRead(d.file.info.mode) {
+				return nil, fs.ErrPermission
+			}
+			l.prefix = d.file.path + "/"
+			l.hi = d.file.path + "0" // '0' follows '/'
+			l.cursor = l.prefix
+			query += ` AND name<?`
+		}
+		query += ` AND ` + sqlValidName + ` ORDER BY name`
+		var err error
+		if l.stmt, err = ar.db.Prepare(query); err != nil {
+			return nil, err
+		}
+		d.lazy = l
+	}
+
+	var entries []fs.DirEntry
+	if !d.lazy.done {
+		var err error
+		if entries, err = d.fetch(n); err != nil {
+			return nil, err
+		}
+	}
+	if n > 0 && len(entries) == 0 {
+		return []fs.DirEntry{}, io.EOF
+	}
+	if entries == nil {
+		entries = []fs.DirEntry{}
+	}
+	return entries, nil
+}
+
+// fetch reads the rows following the cursor until n entries (all if n <= 0) are found.
+func (d *dir) fetch(n int) ([]fs.DirEntry, error) {
+	ar, l := d.file.fs, d.lazy
+	var rows *sql.Rows
+	var err error
+	if l.hi == "" {
+		rows, err = l.stmt.Query(l.cursor)
+	} else {
+		rows, err = l.stmt.Query(l.cursor, l.hi)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []fs.DirEntry
+	for n <= 0 || len(entries) < n {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			l.done = true
+			break
+		}
+		var name string
+		fi := ar.newFileinfo()
+		if err := rows.Scan(&name, &fi.mode, &fi.mtime, &fi.sz); err != nil {
+			return nil, err
+		}
+		l.cursor = name
+		if !fs.ValidPath(name) { // sqlValidName can't check the UTF-8 encoding
+			continue
+		}
+		rest := name[len(l.prefix):]
+		for len(l.explicit) > 0 && !strings.HasPrefix(rest, l.explicit[len(l.explicit)-1]) {
+			l.explicit = l.explicit[:len(l.explicit)-1]
+		}
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			// Directory implied by the path of its content
+			child := rest[:i]
+			if len(l.explicit) > 0 && l.explicit[len(l.explicit)-1] == child {
+				continue
+			}
+			l.explicit = append(l.explicit, child) // Skip the following rows of the content
+			if ar.hidden(l.prefix+child, dirMode) {
+				continue
+			}
+			fi = ar.dirInfo.store(l.prefix+child, &fileinfo{name: child, mode: dirMode, loc: ar.location})
+		} else {
+			if !isDirMode(fi.mode) && !isRegMode(fi.mode) { // Skip files with broken mode (see sqlModeFilter)
+				continue
+			}
+			fi.name = rest
+			l.explicit = append(l.explicit, rest)
+			if ar.hidden(name, fi.mode) {
+				continue
+			}
+			if fi.IsDir() {
+				fi = ar.dirInfo.store(name, fi)
+			} else if ar.readOnly {
+				fi = ar.fileInfo.store(name, fi)
+			}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+	return entries, rows.Close()
+}
+
+// Close implements interface [fs.File].
+func (d *dir) Close() error {
+	var err error
+	if d.lazy != nil {
+		err = d.lazy.stmt.Close()
+		d.lazy = nil
+	}
+	if err2 := d.file.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
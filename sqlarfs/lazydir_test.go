@@ -0,0 +1,167 @@
+package sqlarfs_test
+
+import (
+	"io"
+	"io/fs"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestLazyReadDir(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar", sqlarfs.WithLazyReadDir())
+	if err := fstest.TestFS(ar, "a.txt", "b.txt", "subdir/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ar.Open("subdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := f.(fs.ReadDirFile)
+	expected, err := fs.ReadDir(ar, "subdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for {
+		entries, err := d.ReadDir(1)
+		if err == io.EOF {
+			if len(entries) != 0 {
+				t.Errorf("got %v with io.EOF", names(entries))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("got %v, expected 1 entry", names(entries))
+		}
+		got = append(got, entries[0].Name())
+	}
+	if strings.Join(got, " ") != names(expected) {
+		t.Errorf("got %v, expected %v", got, names(expected))
+	}
+	if err := f.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := d.ReadDir(1); err == nil {
+		t.Error("ReadDir after Close: error expected")
+	}
+}
+
+// TestLazyReadDirImplied checks the directories implied by the paths of their content,
+// that may be interleaved with other entries in name order.
+func TestLazyReadDirImplied(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "a/hidden.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "d", mode: syscall.S_IFDIR | 0755},
+		entry{name: "d.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("d")},
+		entry{name: "d/x.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")},
+		entry{name: "e.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("e")},
+		entry{name: "e/f/g.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("g")},
+		entry{name: "e/h.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("h")},
+	)
+	ar := sqlarfs.New(db, sqlarfs.WithLazyReadDir())
+
+	f, err := ar.Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []string
+	for {
+		entries, err := f.(fs.ReadDirFile).ReadDir(2)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+	}
+	// In the order of the names of the rows
+	if expected := "a a.txt d d.txt e.txt e"; strings.Join(got, " ") != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+// TestLazyReadDirHuge checks that iterating over a huge directory with ReadDir(n)
+// uses bounded memory.
+func TestLazyReadDirHuge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	const count = 100_000
+	db := createDB(t, sqlarSchema)
+	_, err := db.Exec(``+
+		`WITH RECURSIVE n(i) AS (SELECT 0 UNION ALL SELECT i+1 FROM n WHERE i<?)`+
+		` INSERT INTO sqlar(name,mode,mtime,sz,data)`+
+		` SELECT printf('huge/f%06d.txt',i),33188,0,1,'x' FROM n`, // 33188 = syscall.S_IFREG | 0644
+		count-1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	heapInUse := func() uint64 {
+		var m runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+
+	// The memory used by the listing of the whole directory
+	before := heapInUse()
+	all, err := fs.ReadDir(sqlarfs.New(db), "huge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != count {
+		t.Fatalf("got %d entries, expected %d", len(all), count)
+	}
+	full := heapInUse() - before
+	runtime.KeepAlive(all)
+
+	f, err := sqlarfs.New(db, sqlarfs.WithLazyReadDir()).Open("huge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := f.(fs.ReadDirFile)
+	before = heapInUse()
+	var n int
+	var peak uint64
+	for {
+		entries, err := d.ReadDir(100)
+		n += len(entries)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n%(count/10) == 0 {
+			if inUse := heapInUse(); inUse > before && inUse-before > peak {
+				peak = inUse - before
+			}
+		}
+	}
+	if n != count {
+		t.Errorf("got %d entries, expected %d", n, count)
+	}
+	t.Logf("memory: %d bytes for the full listing, peak of %d bytes with ReadDir(100)", full, peak)
+	if peak > full/10 {
+		t.Errorf("peak memory of %d bytes, expected less than %d", peak, full/10)
+	}
+}
@@ -0,0 +1,45 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// ErrPartialContent is returned (wrapped in an [*io/fs.PathError], along with the cause)
+// when decompression fails partway through the content of a file, with [WithLenientRead].
+// It wraps [ErrCorrupt].
+var ErrPartialContent = fmt.Errorf("%w: content truncated by a decompression error", ErrCorrupt)
+
+// WithLenientRead is an [Option] for [New] that salvages the readable prefix of damaged files:
+// when the decompression of the content of a file fails partway, the bytes successfully
+// decompressed so far are returned with an error wrapping [ErrPartialContent], instead of being discarded.
+// Read returns them as usual, and so do [fs.ReadFile], ReadFileContext and ReadFileToBuffer
+// (which otherwise return no content on error).
+//
+// This is intended for recovery tools: it weakens the integrity guarantees as the caller
+// must check the error to know that the content is incomplete. It is not enabled by default.
+// The limits of [WithMaxDecompressRatio] and [WithMaxDecompressBytes] still apply.
+func WithLenientRead() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.lenientRead = true
+	})
+}
+
+// lenientReader marks the decompression errors of r with ErrPartialContent. See WithLenientRead.
+type lenientReader struct {
+	r    io.ReadCloser
+	path string
+}
+
+func (l *lenientReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = &fs.PathError{Op: "read", Path: l.path, Err: fmt.Errorf("%w: %w", ErrPartialContent, err)}
+	}
+	return n, err
+}
+
+func (l *lenientReader) Close() error {
+	return l.r.Close()
+}
@@ -0,0 +1,69 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestLenientRead(t *testing.T) {
+	content := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 2000)
+	compressed := deflate(t, content)
+	truncated := compressed[:len(compressed)/2]
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "truncated.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: truncated},
+		entry{name: "ok.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed},
+	)
+
+	// Default: the error is not marked, and ReadFileToBuffer returns nothing
+	var buf bytes.Buffer
+	err := newFS(db).ReadFileToBuffer("truncated.txt", &buf)
+	if err == nil || errors.Is(err, sqlarfs.ErrPartialContent) || buf.Len() != 0 {
+		t.Errorf("default: got %d bytes, %v", buf.Len(), err)
+	}
+
+	ar := newFS(db, sqlarfs.WithLenientRead())
+	checkPrefix := func(method string, b []byte, err error) {
+		t.Helper()
+		if !errors.Is(err, sqlarfs.ErrPartialContent) || !errors.Is(err, sqlarfs.ErrCorrupt) {
+			t.Errorf("%s: got error %v, expected ErrPartialContent", method, err)
+		}
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || pathErr.Path != "truncated.txt" {
+			t.Errorf("%s: got error %#v, expected *fs.PathError", method, err)
+		}
+		if len(b) == 0 || len(b) >= len(content) || !bytes.HasPrefix(content, b) {
+			t.Errorf("%s: got %d bytes, expected a prefix of %d bytes", method, len(b), len(content))
+		}
+	}
+
+	b, err := fs.ReadFile(ar, "truncated.txt")
+	checkPrefix("fs.ReadFile", b, err)
+
+	f, err := ar.Open("truncated.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = io.ReadAll(f)
+	f.Close()
+	checkPrefix("Read", b, err)
+
+	b, err = ar.ReadFileContext(context.Background(), "truncated.txt")
+	checkPrefix("ReadFileContext", b, err)
+
+	buf.Reset()
+	err = ar.ReadFileToBuffer("truncated.txt", &buf)
+	checkPrefix("ReadFileToBuffer", buf.Bytes(), err)
+
+	// Undamaged files are not affected
+	if b, err := fs.ReadFile(ar, "ok.txt"); err != nil || !bytes.Equal(b, content) {
+		t.Errorf("ok.txt: got %d bytes, %v", len(b), err)
+	}
+}
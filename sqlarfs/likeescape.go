@@ -0,0 +1,61 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapeLikeChar is the default escape character of the LIKE patterns of queries.
+// It is unlikely to appear in names.
+const escapeLikeChar = "§"
+
+var defaultLikeEscape = newLikeEscape(escapeLikeChar)
+
+// likeEscape is an escape character of LIKE patterns.
+type likeEscape struct {
+	char     string
+	replacer *strings.Replacer
+}
+
+func newLikeEscape(char string) *likeEscape {
+	return &likeEscape{
+		char: char,
+		// The escape character itself must be escaped: in a LIKE pattern,
+		// it would escape the following character.
+		replacer: strings.NewReplacer("%", char+"%", "_", char+"_", char, char+char),
+	}
+}
+
+// WithLikeEscape is an [Option] for [New] that sets the escape character of the LIKE
+// patterns built by the queries of the [FS] (the default is '§').
+//
+// Names that contain the escape character are handled correctly, so this is only
+// needed for a database that has a custom LIKE implementation.
+// c must not be '%', '_' (the wildcards of LIKE), '/' (which the queries append to the
+// escaped paths, as in "dir/%") or a quote.
+func WithLikeEscape(c rune) Option {
+	if c == '%' || c == '_' || c == '/' || c == '\'' || c == 0 || !utf8.ValidRune(c) {
+		panic(fmt.Errorf("sqlarfs.WithLikeEscape: invalid escape character %q", c))
+	}
+	e := newLikeEscape(string(c))
+	return optionFunc(func(ar *arfs) {
+		ar.likeEscape = e
+	})
+}
+
+// escapeChar returns the escape character of LIKE patterns, for the ESCAPE clause.
+func (ar *arfs) escapeChar() string {
+	if ar.likeEscape == nil {
+		return defaultLikeEscape.char
+	}
+	return ar.likeEscape.char
+}
+
+// escapeLike escapes the wildcards of LIKE in s.
+func (ar *arfs) escapeLike(s string) string {
+	if ar.likeEscape == nil {
+		return defaultLikeEscape.replacer.Replace(s)
+	}
+	return ar.likeEscape.replacer.Replace(s)
+}
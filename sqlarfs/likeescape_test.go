@@ -0,0 +1,71 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"path"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestLikeEscape checks names that contain the wildcards of LIKE or the escape character.
+func TestLikeEscape(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	files := []string{"a§b/c.txt", "a%b/d.txt", "a_b/e.txt", "axb/f.txt", "a!b/h.txt", "ab/i.txt"}
+	for _, name := range files {
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")})
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []sqlarfs.Option
+	}{
+		{"default", nil},
+		{"backslash", []sqlarfs.Option{sqlarfs.WithLikeEscape('\\')}},
+		{"bang", []sqlarfs.Option{sqlarfs.WithLikeEscape('!')}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ar := newFS(db, tc.opts...)
+			if err := fstest.TestFS(ar, files...); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range files {
+				dir := path.Dir(name)
+				entries, err := fs.ReadDir(ar, dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(entries) != 1 || dir+"/"+entries[0].Name() != name {
+					t.Errorf("ReadDir(%q): got %v", dir, names(entries))
+				}
+				size, err := ar.DirSize(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if size != 1 {
+					t.Errorf("DirSize(%q): got %d, expected 1", dir, size)
+				}
+				matches, err := fs.Glob(ar, dir+"/*")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(matches) != 1 || matches[0] != name {
+					t.Errorf("Glob(%q): got %q", dir+"/*", matches)
+				}
+			}
+		})
+	}
+
+	for _, c := range []rune{'%', '_', '/', '\''} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithLikeEscape(%q): panic expected", c)
+				}
+			}()
+			sqlarfs.WithLikeEscape(c)
+		}()
+	}
+}
@@ -0,0 +1,44 @@
+package sqlarfs
+
+import "io/fs"
+
+// ListBySize returns the regular files whose size is between min and max (inclusive),
+// sorted by size descending (then by path), using a single query.
+// This is useful for storage management tools, to find the files that use the most space.
+//
+// As the files are from different directories, the Name of each [io/fs.FileInfo] is
+// the path of the file in the archive, not its base name.
+//
+// Files that are not readable under the permission mask, or hidden (see [WithEntryFilter]),
+// are not listed. Permissions of directories are not checked.
+func (ar *arfs) ListBySize(min, max int64) ([]fs.FileInfo, error) {
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+`,mtime,sz`+
+		` FROM `+ar.table()+
+		` WHERE sz BETWEEN ? AND ?`+
+		` AND `+ar.modeFilterReg()+
+		` AND `+sqlValidName+
+		` ORDER BY sz DESC,name`,
+		min, max,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []fs.FileInfo
+	for rows.Next() {
+		fi := ar.newFileinfo()
+		if err := fi.scan(rows.Scan); err != nil {
+			return nil, err
+		}
+		if !fs.ValidPath(fi.name) || !ar.canRead(fi.mode) || ar.hiddenPath(fi.name, fi.mode) {
+			continue
+		}
+		infos = append(infos, fi)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return infos, rows.Close()
+}
@@ -0,0 +1,61 @@
+package sqlarfs_test
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestListBySize(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	for name, size := range map[string]int{
+		"tiny.txt":       1,
+		"small.txt":      100,
+		"dir/medium.bin": 1000,
+		"dir/same.bin":   1000,
+		"large.bin":      5000,
+		"huge.bin":       20000,
+	} {
+		data := strings.Repeat("x", size)
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: int64(size), data: []byte(data)})
+	}
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755, sz: 1000},
+		entry{name: "private.bin", mode: syscall.S_IFREG | 0600, sz: 2000, data: []byte(strings.Repeat("x", 2000))},
+	)
+
+	for _, tc := range []struct {
+		opts     []sqlarfs.Option
+		min, max int64
+		expected string
+	}{
+		{nil, 100, 5000, "large.bin:5000 private.bin:2000 dir/medium.bin:1000 dir/same.bin:1000 small.txt:100"},
+		{[]sqlarfs.Option{sqlarfs.PermOthers}, 100, 5000, "large.bin:5000 dir/medium.bin:1000 dir/same.bin:1000 small.txt:100"},
+		{nil, 0, 1 << 40, "huge.bin:20000 large.bin:5000 private.bin:2000 dir/medium.bin:1000 dir/same.bin:1000 small.txt:100 tiny.txt:1"},
+		{
+			// The content of a hidden directory is hidden too
+			[]sqlarfs.Option{sqlarfs.WithEntryFilter(func(path string, mode uint32) bool { return path != "dir" })},
+			100, 5000, "large.bin:5000 private.bin:2000 small.txt:100",
+		},
+		{nil, 2, 99, ""},
+		{nil, 5000, 100, ""},
+	} {
+		infos, err := newFS(db, tc.opts...).ListBySize(tc.min, tc.max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, fi := range infos {
+			if !fi.Mode().IsRegular() {
+				t.Errorf("%s: got mode %v", fi.Name(), fi.Mode())
+			}
+			got = append(got, fi.Name()+":"+strconv.FormatInt(fi.Size(), 10))
+		}
+		if s := strings.Join(got, " "); s != tc.expected {
+			t.Errorf("[%d, %d]: got %q, expected %q", tc.min, tc.max, s, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithLocation is an [Option] for [New] that sets the location of the modification times
+// of entries (ModTime of [io/fs.FileInfo]). By default, as with [time.Unix], the modification
+// times are in the local time zone of the machine ([time.Local]).
+//
+// This removes the ambiguity for servers that format times without converting them to UTC first.
+func WithLocation(loc *time.Location) Option {
+	if loc == nil {
+		panic(fmt.Errorf("sqlarfs.WithLocation: nil location"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.location = loc
+	})
+}
+
+// newFileinfo allocates a [fileinfo] with the settings of ar.
+func (ar *arfs) newFileinfo() *fileinfo {
+	return &fileinfo{loc: ar.location}
+}
@@ -0,0 +1,42 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		loc = time.FixedZone("JST", 9*3600)
+	}
+	for _, l := range []*time.Location{time.UTC, loc} {
+		ar := openFS(t, "testdata/dir.sqlar", sqlarfs.WithLocation(l))
+		err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if got := info.ModTime().Location(); got != l {
+				t.Errorf("%s: got location %v, expected %v", path, got, l)
+			}
+			info, err = fs.Stat(ar, path)
+			if err != nil {
+				return err
+			}
+			if got := info.ModTime().Location(); got != l {
+				t.Errorf("Stat(%q): got location %v, expected %v", path, got, l)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
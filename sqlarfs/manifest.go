@@ -0,0 +1,85 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ErrSizeMismatch is reported (wrapped in an [*io/fs.PathError]) by [VerifyManifest] for a file
+// whose size differs from the size listed in the manifest.
+var ErrSizeMismatch = errors.New("sqlarfs: size mismatch")
+
+// ManifestEntry is a file listed in a manifest, for [VerifyManifest].
+type ManifestEntry struct {
+	Path   string // Path of a regular file in the archive
+	Size   int64  // Expected size. Negative: not checked
+	SHA256 []byte // Expected SHA-256 hash of the content. nil: not checked
+}
+
+// VerifyManifest reads the manifest file manifestPath of ar (usually an [FS]), parses it with parse,
+// and checks that each listed file exists in ar, as a regular file with the expected size and
+// SHA-256 hash. The format of the manifest is defined by parse.
+//
+// Discrepancies are reported, in the order of the manifest, as [*io/fs.PathError]s that wrap
+// [fs.ErrNotExist], [fs.ErrInvalid] (not a regular file), [ErrSizeMismatch], [ErrHashMismatch],
+// or the error that occurred while reading the file.
+// The error is not nil only if the manifest can't be read or parsed.
+func VerifyManifest(ar fs.FS, manifestPath string, parse func([]byte) ([]ManifestEntry, error)) ([]error, error) {
+	manifest, err := fs.ReadFile(ar, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parse(manifest)
+	if err != nil {
+		return nil, &fs.PathError{Op: "parse", Path: manifestPath, Err: err}
+	}
+
+	var errs []error
+	for _, e := range entries {
+		if err := verifyManifestEntry(ar, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs, nil
+}
+
+// verifyManifestEntry checks that file e.Path in ar matches e.
+func verifyManifestEntry(ar fs.FS, e ManifestEntry) error {
+	if !fs.ValidPath(e.Path) {
+		return &fs.PathError{Op: "verify", Path: e.Path, Err: fs.ErrInvalid}
+	}
+	f, err := ar.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return &fs.PathError{Op: "verify", Path: e.Path, Err: fs.ErrInvalid}
+	}
+	if e.Size >= 0 && info.Size() != e.Size {
+		return &fs.PathError{Op: "verify", Path: e.Path, Err: ErrSizeMismatch}
+	}
+	if e.SHA256 == nil {
+		return nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) {
+			// Report which file failed
+			err = &fs.PathError{Op: "read", Path: e.Path, Err: err}
+		}
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), e.SHA256) {
+		return &fs.PathError{Op: "verify", Path: e.Path, Err: ErrHashMismatch}
+	}
+	return nil
+}
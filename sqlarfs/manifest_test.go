@@ -0,0 +1,96 @@
+package sqlarfs_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// parseJSONManifest parses a manifest in JSON: [{"path":"a.txt","size":1,"sha256":"..."}, ...].
+func parseJSONManifest(b []byte) ([]sqlarfs.ManifestEntry, error) {
+	var list []struct {
+		Path   string `json:"path"`
+		Size   *int64 `json:"size"`
+		SHA256 string `json:"sha256"`
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
+	entries := make([]sqlarfs.ManifestEntry, len(list))
+	for i, e := range list {
+		entries[i] = sqlarfs.ManifestEntry{Path: e.Path, Size: -1}
+		if e.Size != nil {
+			entries[i].Size = *e.Size
+		}
+		if e.SHA256 != "" {
+			sum, err := hex.DecodeString(e.SHA256)
+			if err != nil {
+				return nil, err
+			}
+			entries[i].SHA256 = sum
+		}
+	}
+	return entries, nil
+}
+
+func TestVerifyManifest(t *testing.T) {
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	manifest := `[
+		{"path": "a.txt", "size": 5, "sha256": "` + sum("hello") + `"},
+		{"path": "dir/b.txt", "size": 5, "sha256": "` + sum("world") + `"},
+		{"path": "tampered.txt", "size": 5, "sha256": "` + sum("right") + `"},
+		{"path": "resized.txt", "size": 4},
+		{"path": "nohash.txt"},
+		{"path": "missing.txt", "size": 0},
+		{"path": "dir"}
+	]`
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: ".sqlar.json", mode: syscall.S_IFREG | 0644, sz: int64(len(manifest)), data: []byte(manifest)},
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("hello")},
+		entry{name: "dir/b.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("world")},
+		entry{name: "tampered.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("wrong")},
+		entry{name: "resized.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("12345")},
+		entry{name: "nohash.txt", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("abc")},
+	)
+	ar := sqlarfs.New(db)
+
+	errs, err := sqlarfs.VerifyManifest(ar, ".sqlar.json", parseJSONManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		path string
+		err  error
+	}{
+		{"tampered.txt", sqlarfs.ErrHashMismatch},
+		{"resized.txt", sqlarfs.ErrSizeMismatch},
+		{"missing.txt", fs.ErrNotExist},
+		{"dir", fs.ErrInvalid},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("got %d errors, expected %d: %v", len(errs), len(expected), errs)
+	}
+	for i, e := range expected {
+		var pathErr *fs.PathError
+		if !errors.Is(errs[i], e.err) || !errors.As(errs[i], &pathErr) || pathErr.Path != e.path {
+			t.Errorf("got %v, expected %s: %v", errs[i], e.path, e.err)
+		}
+	}
+
+	if _, err := sqlarfs.VerifyManifest(ar, "MANIFEST", parseJSONManifest); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("MANIFEST: got %v, expected ErrNotExist", err)
+	}
+	if _, err := sqlarfs.VerifyManifest(ar, "a.txt", parseJSONManifest); err == nil {
+		t.Error("a.txt: parse error expected")
+	}
+}
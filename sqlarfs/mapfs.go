@@ -0,0 +1,42 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"testing/fstest"
+)
+
+// ToMapFS reads the whole content of ar into a [testing/fstest.MapFS].
+//
+// File content is decompressed, and mode and modification time are preserved
+// for both files and directories. This allows to snapshot an archive into
+// an in-memory fixture that doesn't depend on SQLite.
+func ToMapFS(ar fs.FS) (fstest.MapFS, error) {
+	m := make(fstest.MapFS)
+	err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f := &fstest.MapFile{
+			Mode:    info.Mode(),
+			ModTime: info.ModTime(),
+		}
+		if !d.IsDir() {
+			if f.Data, err = fs.ReadFile(ar, path); err != nil {
+				return err
+			}
+		}
+		m[path] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
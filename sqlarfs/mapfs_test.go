@@ -0,0 +1,50 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestToMapFS(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar", sqlarfs.PermOwner)
+	files := []string{"a.txt", "b.txt", "subdir", "subdir/c.txt", "subdir/d.txt", "subdir/subdir2", "subdir/subdir2/e.txt", "subdir/subdir2/f.txt"}
+
+	m, err := sqlarfs.ToMapFS(ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(ar, files...); err != nil {
+		t.Fatal("sqlarfs:", err)
+	}
+	if err := fstest.TestFS(m, files...); err != nil {
+		t.Fatal("MapFS:", err)
+	}
+
+	for _, name := range files {
+		info, err := fs.Stat(ar, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := m[name]
+		if f == nil {
+			t.Errorf("%s: missing in MapFS", name)
+			continue
+		}
+		if f.Mode != info.Mode() || !f.ModTime.Equal(info.ModTime()) {
+			t.Errorf("%s: got %s %s, expected %s %s", name, f.Mode, f.ModTime, info.Mode(), info.ModTime())
+		}
+		if !info.IsDir() {
+			data, err := fs.ReadFile(ar, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(f.Data) != string(data) {
+				t.Errorf("%s: got %q, expected %q", name, f.Data, data)
+			}
+		}
+	}
+}
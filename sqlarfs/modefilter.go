@@ -0,0 +1,55 @@
+package sqlarfs
+
+// WithAssumeRegularWhenNoType is an [Option] for [New] that makes entries whose mode has
+// no file type bits (ex: 0644 instead of 0100644, as stored by some naive producers)
+// be handled as regular files, if they have data.
+//
+// By default such entries are considered broken and are ignored.
+func WithAssumeRegularWhenNoType() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.assumeRegular = true
+	})
+}
+
+const (
+	sqlNoType = `(mode&61440=0 AND data IS NOT NULL)` // 61440 = syscall.S_IFMT
+)
+
+// modeExpr returns the SQL expression to select the mode of an entry.
+//
+// The mode may have extra bits beyond the Unix mode bits. See WithPermForUser.
+func (ar *arfs) modeExpr() string {
+	expr := `mode`
+	switch {
+	case ar.modeConvention == ConventionGoFileMode:
+		expr = sqlGoFileMode
+	case ar.assumeRegular:
+		expr = `CASE WHEN ` + sqlNoType + ` THEN mode|32768 ELSE mode END` // 32768 = syscall.S_IFREG
+	}
+	if class := ar.permClassExpr(); class != "" {
+		expr = `(` + expr + `)|` + class
+	}
+	return expr
+}
+
+// modeFilter returns the SQL condition to skip entries with broken mode.
+func (ar *arfs) modeFilter() string {
+	if ar.modeConvention == ConventionGoFileMode {
+		return sqlGoFileModeFilter
+	}
+	if ar.assumeRegular {
+		return `(` + sqlModeFilter + ` OR ` + sqlNoType + `)`
+	}
+	return sqlModeFilter
+}
+
+// modeFilterReg returns the SQL condition to select regular files.
+func (ar *arfs) modeFilterReg() string {
+	if ar.modeConvention == ConventionGoFileMode {
+		return sqlGoFileModeFilterReg
+	}
+	if ar.assumeRegular {
+		return `(` + sqlModeFilterReg + ` OR ` + sqlNoType + `)`
+	}
+	return sqlModeFilterReg
+}
@@ -0,0 +1,50 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestAssumeRegularWhenNoType(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: 0644, sz: 1, data: []byte("a")},
+		entry{name: "dir/b.txt", mode: 0600, sz: 1, data: []byte("b")},
+		entry{name: "nodata", mode: 0644}, // Still broken
+	)
+
+	ar := sqlarfs.New(db)
+	if _, err := ar.Stat("a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("default: got %v, expected fs.ErrNotExist", err)
+	}
+
+	ar = sqlarfs.New(db, sqlarfs.WithAssumeRegularWhenNoType())
+	if err := fstest.TestFS(ar, "a.txt", "dir/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ar.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %v", entries)
+	}
+	info, err := ar.Stat("dir/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %s", info.Mode())
+	}
+	b, err := fs.ReadFile(ar, "a.txt")
+	if err != nil || string(b) != "a" {
+		t.Errorf("got %q, %v", b, err)
+	}
+	if _, err := ar.Stat("nodata"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("nodata: got %v, expected fs.ErrNotExist", err)
+	}
+}
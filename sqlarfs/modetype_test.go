@@ -0,0 +1,89 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestModeType checks that the type of entries is the value of the S_IFMT bits of the mode,
+// not a combination of S_IF* bits.
+func TestModeType(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "file.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("f")},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/in.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("i")},
+		// S_IFLNK (0xA000) includes the bit of S_IFREG
+		entry{name: "link", mode: syscall.S_IFLNK | 0777, sz: 8, data: []byte("file.txt")},
+		entry{name: "dir/link", mode: 0xA000 | 0777, sz: 2, data: []byte("..")},
+		// S_IFSOCK (0xC000) includes the bits of S_IFREG and S_IFDIR
+		entry{name: "socket", mode: syscall.S_IFSOCK | 0755},
+		// S_IFBLK (0x6000) includes the bit of S_IFDIR
+		entry{name: "block", mode: syscall.S_IFBLK | 0660},
+		entry{name: "char", mode: syscall.S_IFCHR | 0660},
+		entry{name: "fifo", mode: syscall.S_IFIFO | 0644},
+	)
+	special := []string{"link", "dir/link", "socket", "block", "char", "fifo"}
+
+	for _, opts := range [][]sqlarfs.Option{nil, {sqlarfs.WithLazyReadDir()}, {sqlarfs.WithAssumeRegularWhenNoType()}} {
+		ar := newFS(db, opts...)
+		if err := fstest.TestFS(ar, "file.txt", "dir/in.txt"); err != nil {
+			t.Error(err)
+		}
+
+		var walked []string
+		err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			walked = append(walked, path)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(walked, " "); got != ". dir dir/in.txt file.txt" {
+			t.Errorf("WalkDir: got %q", got)
+		}
+
+		for _, name := range special {
+			if fi, err := ar.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat(%q): got %v, %v, expected ErrNotExist", name, fi, err)
+			}
+			if _, err := ar.ReadFile(name); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("ReadFile(%q): got %v, expected ErrNotExist", name, err)
+			}
+		}
+
+		infos, err := ar.ListBySize(0, 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(infos) != 2 {
+			t.Errorf("ListBySize: got %v, expected the 2 regular files", infos)
+		}
+	}
+
+	broken, err := newFS(db).BrokenEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make(map[string]string)
+	for _, b := range broken {
+		reasons[b.Name] = b.Reason
+	}
+	for _, name := range special {
+		if reasons[name] != "unsupported file type" {
+			t.Errorf("BrokenEntries: %s: got %q", name, reasons[name])
+		}
+	}
+	if len(broken) != len(special) {
+		t.Errorf("BrokenEntries: got %v", broken)
+	}
+}
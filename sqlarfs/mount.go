@@ -0,0 +1,196 @@
+package sqlarfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Mount returns an [io/fs.FS] that presents the content of fsys under directory prefix:
+// entry "a/b" of fsys is "prefix/a/b" in the result. The root of the result contains only
+// the first element of prefix. This is the reverse of [io/fs.Sub] and is useful to compose
+// an archive into a larger virtual filesystem.
+//
+// prefix must be a valid path (see [io/fs.ValidPath]) other than ".", optionally with a trailing '/'.
+// Mount panics if prefix is invalid.
+//
+// The parent directories of prefix are read-only, with a zero modification time.
+func Mount(fsys fs.FS, prefix string) fs.FS {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !fs.ValidPath(prefix) || prefix == "." {
+		panic(fmt.Errorf("sqlarfs.Mount: invalid prefix %q", prefix))
+	}
+	return &mountFS{fsys: fsys, prefix: prefix}
+}
+
+type mountFS struct {
+	fsys   fs.FS
+	prefix string
+}
+
+var (
+	_ fs.StatFS    = (*mountFS)(nil)
+	_ fs.ReadDirFS = (*mountFS)(nil)
+)
+
+// resolve returns the path in m.fsys of name. If name is a parent of the prefix, it returns
+// instead the name of the only entry of that virtual directory.
+func (m *mountFS) resolve(op, name string) (inner string, child string, err error) {
+	if !fs.ValidPath(name) {
+		return "", "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	switch {
+	case name == m.prefix:
+		return ".", "", nil
+	case strings.HasPrefix(name, m.prefix+"/"):
+		return name[len(m.prefix)+1:], "", nil
+	case name == ".":
+		child, _, _ = strings.Cut(m.prefix, "/")
+		return "", child, nil
+	case strings.HasPrefix(m.prefix, name+"/"):
+		child, _, _ = strings.Cut(m.prefix[len(name)+1:], "/")
+		return "", child, nil
+	default:
+		return "", "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+}
+
+// fixErr replaces the path in errors from m.fsys.
+func (m *mountFS) fixErr(name string, err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return &fs.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+	return err
+}
+
+// mountDirInfo returns the [io/fs.FileInfo] of virtual directory name.
+func mountDirInfo(name string) *fileinfo {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return &fileinfo{name: name, mode: dirMode}
+}
+
+// Open implements interface [fs.FS].
+func (m *mountFS) Open(name string) (fs.File, error) {
+	inner, child, err := m.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if child != "" {
+		return &mountDir{info: mountDirInfo(name), child: mountDirInfo(child)}, nil
+	}
+	f, err := m.fsys.Open(inner)
+	if err != nil {
+		return nil, m.fixErr(name, err)
+	}
+	if inner == "." {
+		// Rename the root of fsys
+		return &renamedDir{File: f, name: mountDirInfo(name).name}, nil
+	}
+	return f, nil
+}
+
+// Stat implements interface [fs.StatFS].
+func (m *mountFS) Stat(name string) (fs.FileInfo, error) {
+	inner, child, err := m.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if child != "" {
+		return mountDirInfo(name), nil
+	}
+	info, err := fs.Stat(m.fsys, inner)
+	if err != nil {
+		return nil, m.fixErr(name, err)
+	}
+	if inner == "." {
+		// Rename the root of fsys
+		return &renamedInfo{FileInfo: info, name: mountDirInfo(name).name}, nil
+	}
+	return info, nil
+}
+
+// ReadDir implements interface [fs.ReadDirFS].
+func (m *mountFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	inner, child, err := m.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if child != "" {
+		return []fs.DirEntry{fs.FileInfoToDirEntry(mountDirInfo(child))}, nil
+	}
+	entries, err := fs.ReadDir(m.fsys, inner)
+	return entries, m.fixErr(name, err)
+}
+
+// renamedInfo overrides the name of an [io/fs.FileInfo].
+type renamedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (ri *renamedInfo) Name() string {
+	return ri.name
+}
+
+// renamedDir overrides the name of the root directory of the [io/fs.FS] of a [mountFS].
+type renamedDir struct {
+	fs.File
+	name string
+}
+
+// Stat implements interface [fs.File].
+func (d *renamedDir) Stat() (fs.FileInfo, error) {
+	info, err := d.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &renamedInfo{FileInfo: info, name: d.name}, nil
+}
+
+// ReadDir implements interface [fs.ReadDirFile].
+func (d *renamedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rd, ok := d.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrInvalid}
+	}
+	return rd.ReadDir(n)
+}
+
+// mountDir is a virtual parent directory of the prefix of a [mountFS].
+type mountDir struct {
+	info  *fileinfo
+	child *fileinfo // The only entry, nil once read
+}
+
+// Stat implements interface [fs.File].
+func (d *mountDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// Read implements interface [fs.File].
+func (d *mountDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// Close implements interface [fs.File].
+func (d *mountDir) Close() error {
+	return nil
+}
+
+// ReadDir implements interface [fs.ReadDirFile].
+func (d *mountDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.child == nil {
+		if n > 0 {
+			return nil, io.EOF
+		}
+		return []fs.DirEntry{}, nil
+	}
+	child := d.child
+	d.child = nil
+	return []fs.DirEntry{fs.FileInfoToDirEntry(child)}, nil
+}
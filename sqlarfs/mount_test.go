@@ -0,0 +1,57 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestMount(t *testing.T) {
+	ar := openFS(t, "testdata/simple.sqlar", sqlarfs.PermOwner)
+	m := sqlarfs.Mount(ar, "assets/")
+
+	var paths []string
+	err := fs.WalkDir(m, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(paths)
+	if len(paths) != 4 || paths[1] != "assets" || paths[2] != "assets/bar.txt" || paths[3] != "assets/foo.txt" {
+		t.Errorf("unexpected walk: %q", paths)
+	}
+
+	if err := fstest.TestFS(m, "assets", "assets/foo.txt", "assets/bar.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	deep := sqlarfs.Mount(ar, "static/assets")
+	if err := fstest.TestFS(deep, "static/assets/foo.txt", "static/assets/bar.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"foo.txt", "assets2/foo.txt", "asset"} {
+		if _, err := fs.Stat(m, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: got %v, expected ErrNotExist", name, err)
+		}
+	}
+
+	for _, prefix := range []string{"", ".", "/assets", "a/../b"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: panic expected", prefix)
+				}
+			}()
+			sqlarfs.Mount(ar, prefix)
+		}()
+	}
+}
@@ -0,0 +1,34 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithNameColumn is an [Option] for [New] that sets the column of the sqlar table that
+// holds the paths of entries, for schemas that use another column than "name" ("path" for example).
+//
+// The table must not also have a column named "name". The functions that take a
+// database instead of an [FS] (Optimize, RepairDirs) require the canonical column.
+func WithNameColumn(col string) Option {
+	if !isIdentifier(col) {
+		panic(fmt.Errorf("sqlarfs.WithNameColumn: invalid column name %q", col))
+	}
+	if strings.EqualFold(col, "name") {
+		col = ""
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.nameColumn = col
+	})
+}
+
+// table returns the SQL expression of the sqlar table in the FROM clause of queries.
+//
+// With a name column (see WithNameColumn), this is a subquery that renames the column:
+// SQLite flattens it into the outer query, so lookups still use the index of the column.
+func (ar *arfs) table() string {
+	if ar.nameColumn == "" {
+		return `sqlar`
+	}
+	return `(SELECT ` + ar.nameColumn + ` AS name,* FROM sqlar)`
+}
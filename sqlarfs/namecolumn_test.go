@@ -0,0 +1,47 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestNameColumn(t *testing.T) {
+	db := createDB(t, `CREATE TABLE sqlar(path TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB)`)
+	for _, name := range []string{"a.txt", "dir/b.txt", "dir/sub/c.txt"} {
+		if _, err := db.Exec(`INSERT INTO sqlar(path,mode,mtime,sz,data) VALUES(?,33188,0,1,'x')`, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, opts := range [][]sqlarfs.Option{
+		{sqlarfs.WithNameColumn("path")},
+		{sqlarfs.WithNameColumn("path"), sqlarfs.WithLazyReadDir()},
+	} {
+		ar := sqlarfs.New(db, opts...)
+		if err := fstest.TestFS(ar, "a.txt", "dir/b.txt", "dir/sub/c.txt"); err != nil {
+			t.Fatal(err)
+		}
+		info, err := fs.Stat(ar, "dir/b.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name() != "b.txt" {
+			t.Errorf("got name %q", info.Name())
+		}
+	}
+
+	// Without the option
+	if _, err := sqlarfs.NewChecked(db); !errors.Is(err, sqlarfs.ErrSchema) {
+		t.Errorf("got %v, expected ErrSchema", err)
+	}
+
+	// Both columns
+	db = createDB(t, `CREATE TABLE sqlar(path TEXT PRIMARY KEY, name TEXT, mode INT, mtime INT, sz INT, data BLOB)`)
+	if _, err := sqlarfs.NewChecked(db, sqlarfs.WithNameColumn("path")); !errors.Is(err, sqlarfs.ErrSchema) {
+		t.Errorf("got %v, expected ErrSchema", err)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// negativeCacheSize is the maximum number of paths kept in the negative cache.
+const negativeCacheSize = 1024
+
+// WithNegativeCache is an [Option] for [New] that caches, for the duration ttl,
+// the paths that have been found to not exist, to avoid querying the database
+// again for repeated lookups of missing files (ex: a router trying index.html, then index.htm).
+//
+// The cache is bounded in size.
+// As for the cache of directories, this is unsafe if the archive is modified
+// concurrently: use it only with immutable databases.
+func WithNegativeCache(ttl time.Duration) Option {
+	if ttl <= 0 {
+		panic(fmt.Errorf("sqlarfs.WithNegativeCache: invalid TTL"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.negativeCacheTTL = ttl
+	})
+}
+
+// negativeCache records paths that don't exist, until an expiration time.
+type negativeCache struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// has returns true if path has been recorded as not existing and the record has not expired.
+func (nc *negativeCache) has(path string) bool {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	exp, ok := nc.expires[path]
+	if !ok {
+		return false
+	}
+	if time.Now().After(exp) {
+		delete(nc.expires, path)
+		return false
+	}
+	return true
+}
+
+// add records path as not existing for the duration ttl.
+func (nc *negativeCache) add(path string, ttl time.Duration) {
+	now := time.Now()
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.expires == nil {
+		nc.expires = make(map[string]time.Time)
+	} else if len(nc.expires) >= negativeCacheSize {
+		// Purge expired records
+		for p, exp := range nc.expires {
+			if now.After(exp) {
+				delete(nc.expires, p)
+			}
+		}
+		// Still full: start over
+		if len(nc.expires) >= negativeCacheSize {
+			clear(nc.expires)
+		}
+	}
+	nc.expires[path] = now.Add(ttl)
+}
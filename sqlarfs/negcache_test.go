@@ -0,0 +1,52 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestNegativeCache(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	ar := sqlarfs.New(db, sqlarfs.WithNegativeCache(100*time.Millisecond))
+
+	if _, err := ar.Stat("index.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, expected fs.ErrNotExist", err)
+	}
+	insertEntries(t, db, entry{name: "index.html", mode: syscall.S_IFREG | 0644, sz: 0, data: []byte{}})
+	// The miss is cached
+	if _, err := ar.Stat("index.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, expected fs.ErrNotExist", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	// The cached miss has expired
+	if _, err := ar.Stat("index.html"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkNegativeCache(b *testing.B) {
+	db := openDB(b, "testdata/dir.sqlar")
+	for _, bc := range []struct {
+		name string
+		opts []sqlarfs.Option
+	}{
+		{"NoCache", nil},
+		{"Cache", []sqlarfs.Option{sqlarfs.WithNegativeCache(time.Minute)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ar := sqlarfs.New(db, bc.opts...)
+			for i := 0; i < b.N; i++ {
+				for _, name := range []string{"index.html", "index.htm", "subdir/index.html", "subdir/index.htm"} {
+					if _, err := ar.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// ErrNotSQLite is returned (wrapped in an [*io/fs.PathError]) by OpenSqlar when the
+// content of a file is not an SQLite database.
+var ErrNotSQLite = errors.New("sqlarfs: not an SQLite database")
+
+// sqliteMagic is the header of SQLite database files. See https://sqlite.org/fileformat.html
+const sqliteMagic = "SQLite format 3\x00"
+
+// OpenSqlar opens regular file name, an SQLite Archive File stored in the archive, as an [FS]
+// configured with opts, to browse nested archives with the same API.
+//
+// The nested archive is loaded in memory like with [OpenReaderAt]. If no registered driver
+// supports in-memory databases, it is written to a temporary file instead.
+// [ErrNotSQLite] is returned if the content of the file doesn't start with the header of SQLite databases.
+//
+// The returned [io.Closer] must be called to release the memory (or remove the temporary file)
+// when the FS is not used anymore.
+func (ar *arfs) OpenSqlar(name string, opts ...Option) (FS, io.Closer, error) {
+	var buf bytes.Buffer
+	if err := ar.ReadFileToBuffer(name, &buf); err != nil {
+		return nil, nil, err
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte(sqliteMagic)) {
+		return nil, nil, &fs.PathError{Op: "open", Path: name, Err: ErrNotSQLite}
+	}
+
+	nested, closer, err := OpenReaderAt(bytes.NewReader(data), int64(len(data)), opts...)
+	if errors.Is(err, ErrNoMemoryDriver) {
+		nested, closer, err = openTempSqlar(data, opts...)
+	}
+	if err != nil {
+		return nil, nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return nested, closer, nil
+}
+
+// openTempSqlar writes the SQLite Archive File data to a temporary file and opens it.
+func openTempSqlar(data []byte, opts ...Option) (FS, io.Closer, error) {
+	f, err := os.CreateTemp("", "sqlarfs-*.sqlar")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmp := &tempDB{path: f.Name()}
+	_, err = f.Write(data)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		tmp.Close()
+		return nil, nil, err
+	}
+
+	nested, db, err := openFileSqlar(tmp.path, opts...)
+	if err != nil {
+		tmp.Close()
+		return nil, nil, err
+	}
+	tmp.DB = db
+	return nested, tmp, nil
+}
+
+// tempDB is a database in a temporary file, removed on Close.
+type tempDB struct {
+	*sql.DB
+	path string
+}
+
+func (t *tempDB) Close() error {
+	var err error
+	if t.DB != nil {
+		err = t.DB.Close()
+	}
+	if err2 := os.Remove(t.path); err == nil {
+		err = err2
+	}
+	return err
+}
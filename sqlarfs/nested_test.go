@@ -0,0 +1,47 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenSqlar(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "nested/simple.sqlar", mode: syscall.S_IFREG | 0644, sz: int64(len(simpleSqlar)), data: deflate(t, simpleSqlar)},
+		entry{name: "text.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("hello")},
+		entry{name: "secret.sqlar", mode: syscall.S_IFREG | 0600, sz: int64(len(simpleSqlar)), data: simpleSqlar},
+	)
+	ar := newFS(db, sqlarfs.PermOthers)
+
+	nested, closer, err := ar.OpenSqlar("nested/simple.sqlar", sqlarfs.WithReadOnlyGuarantee())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := closer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := fstest.TestFS(nested, "foo.txt", "bar.txt"); err != nil {
+		t.Error(err)
+	}
+	if !nested.(sqlarfs.ConfigFS).Config().ReadOnlyGuarantee {
+		t.Error("options not applied")
+	}
+
+	if _, _, err := ar.OpenSqlar("text.txt"); !errors.Is(err, sqlarfs.ErrNotSQLite) {
+		t.Errorf("text.txt: got %v, expected ErrNotSQLite", err)
+	}
+	if _, _, err := ar.OpenSqlar("secret.sqlar"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("secret.sqlar: got %v, expected ErrPermission", err)
+	}
+	if _, _, err := ar.OpenSqlar("nested"); err == nil {
+		t.Error("nested: error expected")
+	}
+}
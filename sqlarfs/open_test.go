@@ -0,0 +1,48 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestOpenReadDirFile(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+
+	for _, name := range []string{".", "subdir", "subdir/subdir2"} {
+		f, err := ar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, ok := f.(fs.ReadDirFile)
+		if !ok {
+			t.Errorf("%s: %T is not fs.ReadDirFile", name, f)
+			f.Close()
+			continue
+		}
+		entries, err := d.ReadDir(-1)
+		if err != nil || len(entries) == 0 {
+			t.Errorf("%s: ReadDir: %d entries, %v", name, len(entries), err)
+		}
+		if _, err := f.Read(make([]byte, 1)); err == nil {
+			t.Errorf("%s: Read: error expected", name)
+		}
+		f.Close()
+		if _, err := d.ReadDir(-1); !errors.Is(err, fs.ErrClosed) {
+			t.Errorf("%s: ReadDir after Close: got %v, expected ErrClosed", name, err)
+		}
+	}
+
+	f, err := ar.Open("subdir/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, ok := f.(fs.ReadDirFile); ok {
+		t.Errorf("%T is fs.ReadDirFile", f)
+	}
+	if _, err := io.ReadAll(f); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,33 @@
+package sqlarfs
+
+import (
+	"context"
+	"io/fs"
+)
+
+// OpenAs is like Open, but permissions are enforced with mask (one of [PermOwner], [PermGroup],
+// [PermOthers], [PermAny]) instead of the permissions of the [FS] (including [WithPermForUser]),
+// for this call only: to let an administrator read a file that the FS would deny,
+// without building another FS. The caches of metadata are shared with the FS.
+//
+// Reads from the returned file, and listings of the returned directory, are subject to mask.
+// An invalid mask is reported as [fs.ErrInvalid].
+func (ar *arfs) OpenAs(name string, mask PermMask) (fs.File, error) {
+	switch mask {
+	case PermOwner, PermGroup, PermOthers, PermAny:
+	default:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if mask == ar.permMask && (ar.permUser == nil || ar.permOverride) {
+		return ar.OpenContext(context.Background(), name)
+	}
+	return ar.withPermMask(mask).OpenContext(context.Background(), name)
+}
+
+// withPermMask returns a view of ar that enforces permissions with mask.
+// The caches and the limits of concurrent reads and of bandwidth are shared with ar.
+func (ar *arfs) withPermMask(mask PermMask) *arfs {
+	v := &arfs{db: ar.db, options: ar.options, caches: ar.caches, permOverride: true, reads: ar.reads, rate: ar.rate}
+	v.permMask = mask
+	return v
+}
@@ -0,0 +1,59 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenAs(t *testing.T) {
+	ar := openFS(t, "testdata/perms.sqlar", sqlarfs.PermOthers)
+	if _, err := fs.ReadFile(ar, "user/u.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("got %v, expected ErrPermission", err)
+	}
+	expected, err := fs.ReadFile(openFS(t, "testdata/perms.sqlar"), "user/u.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ar.OpenAs("user/u.txt", sqlarfs.PermOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(expected) {
+		t.Errorf("got %q, expected %q", b, expected)
+	}
+
+	// Listing of a directory opened with the override
+	f, err = ar.OpenAs("user", sqlarfs.PermOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := f.(fs.ReadDirFile).ReadDir(-1)
+	f.Close()
+	if err != nil || names(entries) != "u.txt" {
+		t.Errorf("user: got %q, %v", names(entries), err)
+	}
+
+	// The FS still enforces its own mask
+	if _, err := fs.ReadFile(ar, "user/u.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("got %v, expected ErrPermission", err)
+	}
+	// The override can also restrict
+	// (the parent directory can't be traversed)
+	if _, err := ar.OpenAs("others/o.txt", sqlarfs.PermOwner); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("others/o.txt: got %v, expected ErrPermission", err)
+	}
+
+	if _, err := ar.OpenAs("user/u.txt", 0o755); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("got %v, expected ErrInvalid", err)
+	}
+}
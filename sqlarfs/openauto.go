@@ -0,0 +1,95 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// OpenAuto opens an SQLite Archive File from source, whatever the way the archive is available,
+// choosing the open strategy from the type of source:
+//   - string: the path of a file, opened read-only with the first registered [database/sql]
+//     driver that can read it;
+//   - []byte: the content of the archive, loaded in memory like with [OpenReaderAt];
+//   - an [io.ReaderAt] with a method Size() int64 (such as [*bytes.Reader], [*strings.Reader],
+//     [*io.SectionReader]) or a method Stat() (fs.FileInfo, error) (such as [*os.File]):
+//     the content of the archive, loaded in memory like with [OpenReaderAt];
+//   - [*database/sql.DB]: an open database, used directly.
+//
+// The returned [io.Closer] must be called when the FS is not used anymore. It releases
+// the resources allocated by OpenAuto, but it doesn't close a [*database/sql.DB] given as source,
+// which is still owned by the caller.
+//
+// The schema of the archive is checked (see [ErrSchema]). [ErrNoMemoryDriver] is returned
+// (wrapped) for a reader source if no registered driver supports in-memory databases.
+func OpenAuto(source any, opts ...Option) (FS, io.Closer, error) {
+	switch src := source.(type) {
+	case *sql.DB:
+		ar := New(src, opts...)
+		if err := ar.(*arfs).checkSchema(); err != nil {
+			return nil, nil, err
+		}
+		return ar, io.NopCloser(nil), nil
+	case string:
+		if _, err := os.Stat(src); err != nil {
+			return nil, nil, err
+		}
+		ar, db, err := openFileSqlar(src, opts...)
+		if err != nil {
+			return nil, nil, &fs.PathError{Op: "open", Path: src, Err: err}
+		}
+		return ar, db, nil
+	case []byte:
+		return openAutoReaderAt(bytes.NewReader(src), int64(len(src)), opts...)
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		return openAutoReaderAt(src, src.Size(), opts...)
+	case interface {
+		io.ReaderAt
+		Stat() (fs.FileInfo, error)
+	}:
+		info, err := src.Stat()
+		if err != nil {
+			return nil, nil, err
+		}
+		return openAutoReaderAt(src, info.Size(), opts...)
+	case io.ReaderAt:
+		return nil, nil, fmt.Errorf("sqlarfs.OpenAuto: size of %T is unknown: use an io.SectionReader", source)
+	default:
+		return nil, nil, fmt.Errorf("sqlarfs.OpenAuto: unsupported source type %T", source)
+	}
+}
+
+func openAutoReaderAt(r io.ReaderAt, size int64, opts ...Option) (FS, io.Closer, error) {
+	ar, closer, err := OpenReaderAt(r, size, opts...)
+	if errors.Is(err, ErrNoMemoryDriver) {
+		return nil, nil, fmt.Errorf("sqlarfs.OpenAuto: reader source: %w", err)
+	}
+	return ar, closer, err
+}
+
+// openFileSqlar opens the SQLite Archive File at path, read-only, with the first
+// registered [database/sql] driver that can read it.
+func openFileSqlar(path string, opts ...Option) (FS, *sql.DB, error) {
+	err := errors.New("no registered database/sql driver")
+	for _, driverName := range sql.Drivers() {
+		var db *sql.DB
+		db, err = sql.Open(driverName, "file:"+path+"?mode=ro")
+		if err != nil {
+			continue
+		}
+		ar := New(db, opts...)
+		if err = ar.(*arfs).checkSchema(); err != nil {
+			db.Close()
+			continue
+		}
+		return ar, db, nil
+	}
+	return nil, nil, err
+}
@@ -0,0 +1,73 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenAuto(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "db.txt", mode: syscall.S_IFREG | 0644, sz: 2, data: []byte("db")})
+	f, err := os.Open("testdata/simple.sqlar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, tc := range []struct {
+		name   string
+		source any
+		files  []string
+	}{
+		{"path", "testdata/simple.sqlar", []string{"foo.txt", "bar.txt"}},
+		{"bytes", simpleSqlar, []string{"foo.txt", "bar.txt"}},
+		{"reader", bytes.NewReader(simpleSqlar), []string{"foo.txt", "bar.txt"}},
+		{"file", f, []string{"foo.txt", "bar.txt"}},
+		{"db", db, []string{"db.txt"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ar, closer, err := sqlarfs.OpenAuto(tc.source, sqlarfs.WithReadOnlyGuarantee())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := fstest.TestFS(ar, tc.files...); err != nil {
+				t.Error(err)
+			}
+			if !ar.(sqlarfs.ConfigFS).Config().ReadOnlyGuarantee {
+				t.Error("options not applied")
+			}
+			if err := closer.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	// The DB is still owned by the caller
+	if err := db.Ping(); err != nil {
+		t.Errorf("db closed: %v", err)
+	}
+
+	if _, _, err := sqlarfs.OpenAuto("testdata/missing.sqlar"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, expected ErrNotExist", err)
+	}
+	if _, _, err := sqlarfs.OpenAuto("testdata/Makefile"); err == nil {
+		t.Error("not an archive: error expected")
+	}
+	emptyDB := createDB(t)
+	if _, _, err := sqlarfs.OpenAuto(emptyDB); !errors.Is(err, sqlarfs.ErrSchema) {
+		t.Errorf("empty db: got %v, expected ErrSchema", err)
+	}
+	for _, source := range []any{nil, 42, struct{ io.ReaderAt }{bytes.NewReader(simpleSqlar)}} {
+		if _, _, err := sqlarfs.OpenAuto(source); err == nil {
+			t.Errorf("%T: error expected", source)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sqlarfs
+
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
+// OpenConcat returns a reader of the contents of the regular files names, in order,
+// like [io.MultiReader]. Each file is opened when the previous one is exhausted, and closed
+// when it is exhausted itself, so only one file at a time holds a read slot (see [WithMaxConcurrentReads]).
+//
+// The existence and the permissions of all the files are checked before OpenConcat returns:
+// it fails on the first name that can't be read, before any content is read.
+func (ar *arfs) OpenConcat(names ...string) (io.ReadCloser, error) {
+	paths := make([]string, len(names))
+	for i, name := range names {
+		p := ar.cleanPath(name)
+		if !fs.ValidPath(p) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+		}
+		var info *fileinfo
+		var err error
+		if p == "." {
+			info, err = ar.statRoot()
+		} else {
+			info, err = ar.stat(p)
+		}
+		if err == nil && ar.dirOnly(name) && !info.IsDir() {
+			err = fs.ErrNotExist
+		}
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		if info.IsDir() {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+		}
+		if !ar.canRead(info.mode) {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrPermission}
+		}
+		paths[i] = p
+	}
+	return &concatReader{ar: ar, names: paths}, nil
+}
+
+// concatReader reads the contents of several files of an archive in order.
+type concatReader struct {
+	ar    *arfs
+	names []string // Files not yet exhausted
+	cur   fs.File  // File being read (names[0]), nil if not open
+}
+
+func (r *concatReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.names) == 0 {
+				return 0, io.EOF
+			}
+			f, err := r.ar.OpenContext(context.Background(), r.names[0])
+			if err != nil {
+				return 0, err
+			}
+			r.cur = f
+		}
+		n, err := r.cur.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		r.cur.Close()
+		r.cur = nil
+		r.names = r.names[1:]
+		if n > 0 || len(p) == 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *concatReader) Close() error {
+	r.names = nil
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	return err
+}
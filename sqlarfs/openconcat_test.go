@@ -0,0 +1,63 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOpenConcat(t *testing.T) {
+	text := strings.Repeat("compressed line\n", 100)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "log.1", mode: syscall.S_IFREG | 0644, sz: 6, data: []byte("first\n")},
+		entry{name: "log.2", mode: syscall.S_IFREG | 0644, sz: int64(len(text)), data: deflate(t, []byte(text))},
+		entry{name: "empty", mode: syscall.S_IFREG | 0644},
+		entry{name: "secret", mode: syscall.S_IFREG | 0600, sz: 6, data: []byte("secret")},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := newFS(db, sqlarfs.PermOthers, sqlarfs.WithMaxConcurrentReads(1))
+
+	r, err := ar.OpenConcat("log.1", "empty", "log.2", "log.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "first\n" + text + "first\n"; string(b) != expected {
+		t.Errorf("got %q, expected %q", b, expected)
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// Closed early: the read slot is released
+	r, err = ar.OpenConcat("log.1", "log.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err := fs.ReadFile(ar, "log.1"); err != nil {
+		t.Error(err)
+	}
+
+	for name, expected := range map[string]error{
+		"missing": fs.ErrNotExist,
+		"secret":  fs.ErrPermission,
+		"dir":     fs.ErrInvalid,
+	} {
+		if _, err := ar.OpenConcat("log.1", name); !errors.Is(err, expected) {
+			t.Errorf("%s: got %v, expected %v", name, err, expected)
+		}
+	}
+}
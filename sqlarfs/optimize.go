@@ -0,0 +1,175 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// OptimizeOption is an option for Optimize.
+//
+// Available options: [WithOptimizeMinGain], [WithOptimizeFlate].
+type OptimizeOption func(*optimizeOptions)
+
+type optimizeOptions struct {
+	minGain float64 // See WithOptimizeMinGain
+	flate   bool    // See WithOptimizeFlate
+}
+
+// WithOptimizeMinGain is an [OptimizeOption] that sets the minimum fraction of the size of
+// a file (between 0 and 1) that compression must save for the data to be stored compressed.
+//
+// Stored files are compressed only if this gain is reached, and compressed files that
+// don't reach it are stored decompressed, which is faster to read.
+// The default is 0: as with the sqlite3 command-line tool, a file is stored
+// compressed if this is smaller, and compressed files are left as is.
+func WithOptimizeMinGain(gain float64) OptimizeOption {
+	if gain < 0 || gain >= 1 {
+		panic(fmt.Errorf("sqlarfs.WithOptimizeMinGain: invalid gain"))
+	}
+	return func(o *optimizeOptions) {
+		o.minGain = gain
+	}
+}
+
+// WithOptimizeFlate is an [OptimizeOption] that compresses data as raw DEFLATE streams
+// (RFC 1951, the default format of this package) instead of zlib streams if the archive
+// has no compressed entry yet. The archive is then readable without [WithSQLiteExtCompat],
+// but not by the sqlar extension of SQLite.
+func WithOptimizeFlate() OptimizeOption {
+	return func(o *optimizeOptions) {
+		o.flate = true
+	}
+}
+
+// Optimize applies retroactively to the regular files of the sqlar table of db the
+// compression heuristic of the sqlite3 command-line tool: files stored uncompressed that
+// shrink under DEFLATE are compressed, and compressed files that don't shrink enough
+// (see [WithOptimizeMinGain]) are decompressed. It returns the number of bytes reclaimed
+// in the 'data' column (negative if decompression makes the data bigger).
+//
+// Data is compressed in the format of the compressed entries of the archive (see
+// CompressionFormat). If the archive has no compressed entry yet, data is compressed as
+// zlib streams (RFC 1950), the format of the sqlar extension of SQLite used by the sqlite3
+// command-line tool: read the result with [WithSQLiteExtCompat] (see also [WithOptimizeFlate]).
+// If its compressed entries are in other formats (see [RegisterDecompressor]), data is
+// compressed as raw DEFLATE streams. An archive that would end up mixing zlib and raw
+// DEFLATE is rejected. Modes and modification times are preserved. Files whose data
+// can't be decompressed are left as is (see BrokenEntries).
+// Optimize runs in a transaction. The data must not be encoded (see [WithDataEncoding]).
+func Optimize(db *sql.DB, opts ...OptimizeOption) (reclaimed int64, err error) {
+	var o optimizeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Select the candidates first, as the table is updated
+	rows, err := tx.Query(`` +
+		`SELECT name,sz,length(data)` +
+		` FROM sqlar` +
+		` WHERE ` + sqlModeFilterReg +
+		` AND sz>0 AND length(data)<=sz`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var names []string
+	compressed := false // Some data is compressed
+	for rows.Next() {
+		var (
+			name   string
+			sz, ln int64
+		)
+		if err := rows.Scan(&name, &sz, &ln); err != nil {
+			return 0, err
+		}
+		compressed = compressed || ln < sz
+		if ln == sz || float64(sz-ln) < o.minGain*float64(sz) {
+			names = append(names, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	ar := &arfs{db: tx, options: options{permMask: PermAny}, caches: new(caches)}
+	format, err := ar.CompressionFormat()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case format == FormatUnknown && !compressed:
+		format = FormatZlib
+		if o.flate {
+			format = FormatFlate
+		}
+	case format == FormatMixed, format == FormatZlib && o.flate:
+		return 0, fmt.Errorf("sqlarfs.Optimize: mixed compression formats")
+	}
+	ar.sqliteExtCompat = format == FormatZlib
+
+	var buf bytes.Buffer
+	var fw interface {
+		io.WriteCloser
+		Reset(io.Writer)
+	}
+	if ar.sqliteExtCompat {
+		fw = zlib.NewWriter(&buf)
+	} else if fw, err = flate.NewWriter(&buf, flate.DefaultCompression); err != nil {
+		return 0, err
+	}
+	for _, name := range names {
+		var (
+			sz   int64
+			data []byte
+		)
+		if err := tx.QueryRow(`SELECT sz,data FROM sqlar WHERE name=?`, name).Scan(&sz, &data); err != nil {
+			return 0, err
+		}
+
+		var newData []byte
+		if int64(len(data)) == sz {
+			buf.Reset()
+			fw.Reset(&buf)
+			if _, err := fw.Write(data); err != nil {
+				return 0, err
+			}
+			if err := fw.Close(); err != nil {
+				return 0, err
+			}
+			if int64(buf.Len()) >= sz || float64(sz-int64(buf.Len())) < o.minGain*float64(sz) {
+				continue
+			}
+			newData = buf.Bytes()
+		} else {
+			r, err := ar.dataReader(name, data, sz)
+			if err != nil {
+				continue
+			}
+			content := make([]byte, 0, ar.contentBufCap(sz))
+			// Read at most one byte more than expected
+			content, err = readAll(io.LimitReader(r, sz+1), content)
+			r.Close()
+			if err != nil || int64(len(content)) != sz {
+				continue
+			}
+			newData = content
+		}
+
+		if _, err := tx.Exec(`UPDATE sqlar SET data=? WHERE name=?`, newData, name); err != nil {
+			return 0, err
+		}
+		reclaimed += int64(len(data) - len(newData))
+	}
+	return reclaimed, tx.Commit()
+}
@@ -0,0 +1,167 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"database/sql"
+	"io"
+	"io/fs"
+	"math/rand"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestOptimize(t *testing.T) {
+	compressible := bytes.Repeat([]byte("Hello world\n"), 100)
+	random := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(random)
+	// Compression saves about 10%
+	poor := append(random[:900:900], make([]byte, 100)...)
+
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "compressible.txt", mode: syscall.S_IFREG | 0600, mtime: 1234, sz: int64(len(compressible)), data: compressible},
+		entry{name: "random.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(random)), data: random},
+		entry{name: "poor.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(poor)), data: deflate(t, poor)},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	dataLen := func(name string) (n int64) {
+		t.Helper()
+		if err := db.QueryRow(`SELECT length(data) FROM sqlar WHERE name=?`, name).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	check := func() {
+		t.Helper()
+		ar := sqlarfs.New(db)
+		for name, content := range map[string][]byte{"compressible.txt": compressible, "random.bin": random, "poor.bin": poor} {
+			b, err := fs.ReadFile(ar, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, content) {
+				t.Errorf("%s: content mismatch", name)
+			}
+		}
+		info, err := fs.Stat(ar, "compressible.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode() != 0600 || info.ModTime().Unix() != 1234 {
+			t.Errorf("compressible.txt: mode and mtime not preserved: %v %v", info.Mode(), info.ModTime().Unix())
+		}
+	}
+
+	poorLen := dataLen("poor.bin")
+	reclaimed, err := sqlarfs.Optimize(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := dataLen("compressible.txt"); n >= int64(len(compressible)) {
+		t.Errorf("compressible.txt: not compressed (%d bytes)", n)
+	} else if expected := int64(len(compressible)) - n; reclaimed != expected {
+		t.Errorf("got %d bytes reclaimed, expected %d", reclaimed, expected)
+	}
+	if n := dataLen("random.bin"); n != int64(len(random)) {
+		t.Errorf("random.bin: got %d bytes, expected to stay stored", n)
+	}
+	if n := dataLen("poor.bin"); n != poorLen {
+		t.Errorf("poor.bin: got %d bytes, expected to stay compressed", n)
+	}
+	check()
+
+	// Idempotent
+	if reclaimed, err := sqlarfs.Optimize(db); err != nil || reclaimed != 0 {
+		t.Errorf("second run: got %d, %v", reclaimed, err)
+	}
+
+	reclaimed, err = sqlarfs.Optimize(db, sqlarfs.WithOptimizeMinGain(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := dataLen("poor.bin"); n != int64(len(poor)) {
+		t.Errorf("poor.bin: got %d bytes, expected to be decompressed", n)
+	}
+	if expected := poorLen - int64(len(poor)); reclaimed != expected {
+		t.Errorf("got %d bytes reclaimed, expected %d", reclaimed, expected)
+	}
+	check()
+}
+
+// TestOptimizeZlib checks that the compressed data of archives created by the sqlite3
+// command-line tool stays readable by it.
+func TestOptimizeZlib(t *testing.T) {
+	compressible := bytes.Repeat([]byte("Hello world\n"), 100)
+	zlibbed := bytes.Repeat([]byte("zlib "), 100)
+
+	isZlib := func(db *sql.DB, name string) bool {
+		t.Helper()
+		var data []byte
+		if err := db.QueryRow(`SELECT data FROM sqlar WHERE name=?`, name).Scan(&data); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return false
+		}
+		b, err := io.ReadAll(zr)
+		return err == nil && bytes.Equal(b, compressible)
+	}
+
+	// The format is detected from the existing entries
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "compressible.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(compressible)), data: compressible},
+		entry{name: "zlib.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(zlibbed)), data: zlibCompress(t, zlibbed)},
+	)
+	if _, err := sqlarfs.Optimize(db); err != nil {
+		t.Fatal(err)
+	}
+	if !isZlib(db, "compressible.txt") {
+		t.Error("compressible.txt: not compressed with zlib")
+	}
+	if err := fstest.TestFS(sqlarfs.New(db, sqlarfs.WithSQLiteExtCompat()), "compressible.txt", "zlib.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default for an archive without compressed entries
+	db = createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "compressible.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(compressible)), data: compressible},
+	)
+	if _, err := sqlarfs.Optimize(db); err != nil {
+		t.Fatal(err)
+	}
+	if !isZlib(db, "compressible.txt") {
+		t.Error("compressible.txt: not compressed with zlib")
+	}
+
+	// Raw DEFLATE on request
+	db = createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "compressible.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(compressible)), data: compressible},
+	)
+	if _, err := sqlarfs.Optimize(db, sqlarfs.WithOptimizeFlate()); err != nil {
+		t.Fatal(err)
+	}
+	if isZlib(db, "compressible.txt") {
+		t.Error("compressible.txt: compressed with zlib")
+	}
+	if err := fstest.TestFS(sqlarfs.New(db), "compressible.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rejected for an archive with zlib entries
+	db = createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "compressible.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(compressible)), data: compressible},
+		entry{name: "zlib.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(zlibbed)), data: zlibCompress(t, zlibbed)},
+	)
+	if _, err := sqlarfs.Optimize(db, sqlarfs.WithOptimizeFlate()); err == nil {
+		t.Error("error expected")
+	}
+}
@@ -0,0 +1,103 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"unicode/utf8"
+)
+
+// Page returns, in name order, up to limit entries of directory dir with a name greater than after,
+// and the cursor to pass as after to get the next page ("" after the last page).
+//
+// This is keyset pagination, that is efficient even for deep pages of huge directories.
+// Pass "" as after to get the first page. Directories implied by the paths of their content
+// are synthesized as in ReadDir. Hidden entries (see [WithEntryFilter]) may make a page
+// shorter than limit.
+func (ar *arfs) Page(dir, after string, limit int) ([]fs.DirEntry, string, error) {
+	dir = ar.cleanPath(dir)
+	if !fs.ValidPath(dir) || limit <= 0 {
+		return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrInvalid}
+	}
+	var prefix string
+	if dir == "." {
+		if _, err := ar.statRoot(); err != nil {
+			return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: err}
+		}
+	} else {
+		fi, err := ar.stat(dir)
+		if err != nil {
+			return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: err}
+		}
+		if !fi.IsDir() {
+			return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrInvalid}
+		}
+		if !ar.canRead(fi.mode) {
+			return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrPermission}
+		}
+		prefix = dir + "/"
+	}
+
+	// The child of the directory is the first segment of the rest of the name.
+	// For each child, the aggregate MAX(direct) selects the row of the entry itself
+	// (a file wins over the directory implied by the paths of other entries, see ReadDir).
+	prefixEsc := ar.escapeLike(prefix)
+	rows, err := ar.db.Query(``+
+		`SELECT child,MAX(direct),mode,mtime,sz`+
+		` FROM (`+
+		`SELECT CASE WHEN INSTR(rest,'/')>0 THEN SUBSTR(rest,1,INSTR(rest,'/')-1) ELSE rest END AS child,`+
+		`INSTR(rest,'/')=0 AS direct,mode,mtime,sz`+
+		` FROM (`+
+		`SELECT SUBSTR(name,?) AS rest,`+ar.modeExpr()+` AS mode,mtime,sz`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND name>?`+ // Use the index to start after the cursor
+		` AND `+sqlValidName+
+		` AND (name LIKE ? ESCAPE '`+ar.escapeChar()+`' OR `+ar.modeFilter()+`)`+ // Skip files with broken mode
+		`))`+
+		` WHERE child>?`+
+		` GROUP BY child`+
+		` ORDER BY child`+
+		` LIMIT ?`,
+		1+utf8.RuneCountInString(prefix), // SUBSTR counts characters
+		prefixEsc+"_%",
+		prefix+after,
+		prefixEsc+"%/%",
+		after,
+		limit,
+	)
+	if err != nil {
+		return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: err}
+	}
+	defer rows.Close()
+
+	entries := make([]fs.DirEntry, 0, limit)
+	var n int
+	var last string
+	for rows.Next() {
+		var direct bool
+		fi := ar.newFileinfo()
+		if err := rows.Scan(&fi.name, &direct, &fi.mode, &fi.mtime, &fi.sz); err != nil {
+			return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: err}
+		}
+		n++
+		last = fi.name
+		if !direct {
+			fi.mode, fi.mtime, fi.sz = dirMode, 0, 0
+		}
+		if !fs.ValidPath(prefix+fi.name) || ar.hidden(prefix+fi.name, fi.mode) {
+			continue
+		}
+		if fi.IsDir() {
+			fi = ar.dirInfo.store(prefix+fi.name, fi)
+		} else if ar.readOnly {
+			fi = ar.fileInfo.store(prefix+fi.name, fi)
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", &fs.PathError{Op: "readdir", Path: dir, Err: err}
+	}
+	if n < limit {
+		last = ""
+	}
+	return entries, last, rows.Close()
+}
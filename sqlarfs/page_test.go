@@ -0,0 +1,99 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestPage(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "a-b/c", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("c")},
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "b", mode: syscall.S_IFDIR | 0755},
+		entry{name: "b/x", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")},
+		entry{name: "b/y/z", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("z")},
+		entry{name: "c/d/e/f", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("f")},
+		entry{name: "d", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("d")},
+		entry{name: "d/hidden", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("h")},
+		entry{name: "e", mode: 0644, sz: 1, data: []byte("e")}, // Broken mode
+	)
+
+	for _, opts := range [][]sqlarfs.Option{nil, {sqlarfs.WithReadOnlyGuarantee()}} {
+		for _, root := range []extFS{newFS(db, opts...), openFS(t, "testdata/dir.sqlar")} {
+			for limit := 1; limit <= 4; limit++ {
+				err := fs.WalkDir(root, ".", func(p string, d fs.DirEntry, err error) error {
+					if err != nil || !d.IsDir() {
+						return err
+					}
+					expected, err := root.ReadDir(p)
+					if err != nil {
+						return err
+					}
+					var got []fs.DirEntry
+					var after string
+					for {
+						page, next, err := root.Page(p, after, limit)
+						if err != nil {
+							return err
+						}
+						if len(page) > limit {
+							t.Errorf("%s: page of %d entries", p, len(page))
+						}
+						got = append(got, page...)
+						if next == "" {
+							break
+						}
+						after = next
+					}
+					if names(got) != names(expected) {
+						t.Errorf("%s, limit %d: got %s, expected %s", p, limit, names(got), names(expected))
+					}
+					for i := range got {
+						if i < len(expected) && got[i].Type() != expected[i].Type() {
+							t.Errorf("%s: got type %s, expected %s", path.Join(p, got[i].Name()), got[i].Type(), expected[i].Type())
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	ar := newFS(db)
+	for _, tc := range []struct {
+		dir   string
+		limit int
+		err   error
+	}{
+		{".", 0, fs.ErrInvalid},
+		{"a", 1, fs.ErrInvalid},
+		{"missing", 1, fs.ErrNotExist},
+	} {
+		if _, _, err := ar.Page(tc.dir, "", tc.limit); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, expected %v", tc.dir, err, tc.err)
+		}
+	}
+}
+
+// names returns the names of the entries, separated by spaces.
+func names(entries []fs.DirEntry) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(e.Name())
+	}
+	return b.String()
+}
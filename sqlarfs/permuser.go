@@ -0,0 +1,66 @@
+package sqlarfs
+
+import "strconv"
+
+// WithPermForUser is an [Option] for [New] that enforces permissions like POSIX does
+// for the user uid with primary group gid, using the 'uid' and 'gid' columns of the sqlar
+// table (an extension of the standard schema): the owner bits of the mode of an entry apply if
+// its uid matches, else the group bits if its gid matches, else the others bits.
+//
+// If the sqlar table has no 'uid' and 'gid' columns, the permission mask is [PermAny].
+// Supplementary groups and the privileges of root are not considered.
+func WithPermForUser(uid, gid uint32) Option {
+	return optionFunc(func(ar *arfs) {
+		ar.permMask = PermAny
+		ar.permUser = &permUser{uid: uid, gid: gid}
+	})
+}
+
+// permUser is the identity set with WithPermForUser.
+type permUser struct {
+	uid, gid uint32
+}
+
+// permClassMask is the mask of the bits of the mode (beyond the Unix mode bits) that
+// record the class of permission bits that apply to an entry. See WithPermForUser.
+const (
+	permClassMask   uint32 = 3 << 16
+	permClassOwner  uint32 = 1 << 16
+	permClassGroup  uint32 = 2 << 16
+	permClassOthers uint32 = 3 << 16
+)
+
+// permClassExpr returns the SQL expression of the class of permission bits that apply to an entry
+// (see WithPermForUser), or "" if disabled.
+func (ar *arfs) permClassExpr() string {
+	if ar.permUser == nil {
+		return ""
+	}
+	// If the query of the schema fails, the query that uses the expression will fail too
+	columns, err := ar.columns()
+	if err != nil || !columns["uid"] || !columns["gid"] {
+		return ""
+	}
+	return `CASE` +
+		` WHEN uid=` + strconv.FormatUint(uint64(ar.permUser.uid), 10) + ` THEN ` + strconv.FormatUint(uint64(permClassOwner), 10) +
+		` WHEN gid=` + strconv.FormatUint(uint64(ar.permUser.gid), 10) + ` THEN ` + strconv.FormatUint(uint64(permClassGroup), 10) +
+		` ELSE ` + strconv.FormatUint(uint64(permClassOthers), 10) +
+		` END`
+}
+
+// permMaskFor returns the permission mask that applies to an entry with mode.
+func (ar *arfs) permMaskFor(mode uint32) uint32 {
+	if ar.permOverride {
+		return uint32(ar.permMask)
+	}
+	switch mode & permClassMask {
+	case permClassOwner:
+		return uint32(PermOwner)
+	case permClassGroup:
+		return uint32(PermGroup)
+	case permClassOthers:
+		return uint32(PermOthers)
+	default:
+		return uint32(ar.permMask)
+	}
+}
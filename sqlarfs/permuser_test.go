@@ -0,0 +1,70 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestPermForUser(t *testing.T) {
+	db := createDB(t, `CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB, uid INT, gid INT)`)
+	for _, e := range []struct {
+		name     string
+		mode     uint32
+		uid, gid int
+	}{
+		{"owner.txt", syscall.S_IFREG | 0400, 1000, 50},
+		{"group.txt", syscall.S_IFREG | 0040, 2000, 100},
+		{"others.txt", syscall.S_IFREG | 0004, 2000, 50},
+		{"private", syscall.S_IFDIR | 0700, 2000, 50},
+		{"private/secret.txt", syscall.S_IFREG | 0444, 1000, 100},
+	} {
+		_, err := db.Exec(`INSERT INTO sqlar(name,mode,mtime,sz,data,uid,gid) VALUES(?,?,0,1,'x',?,?)`, e.name, e.mode, e.uid, e.gid)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		uid, gid uint32
+		readable []string
+	}{
+		{1000, 100, []string{"owner.txt", "group.txt", "others.txt"}},
+		{1000, 200, []string{"owner.txt", "others.txt"}},
+		{3000, 100, []string{"group.txt", "others.txt"}},
+		{3000, 200, []string{"others.txt"}},
+		{2000, 50, []string{"private/secret.txt"}}, // The owner class applies even if it grants nothing
+	} {
+		ar := sqlarfs.New(db, sqlarfs.WithPermForUser(tc.uid, tc.gid))
+		readable := make(map[string]bool)
+		for _, name := range tc.readable {
+			readable[name] = true
+		}
+		for _, name := range []string{"owner.txt", "group.txt", "others.txt", "private/secret.txt"} {
+			_, err := fs.ReadFile(ar, name)
+			switch {
+			case readable[name] && err != nil:
+				t.Errorf("%d:%d %s: %v", tc.uid, tc.gid, name, err)
+			case !readable[name] && !errors.Is(err, fs.ErrPermission):
+				t.Errorf("%d:%d %s: got %v, expected ErrPermission", tc.uid, tc.gid, name, err)
+			}
+		}
+		// The mode is reported as is
+		if fi, err := fs.Stat(ar, "private"); err != nil {
+			t.Error(err)
+		} else if fi.Mode() != fs.ModeDir|0700 {
+			t.Errorf("private: got mode %s", fi.Mode())
+		}
+	}
+
+	// Without uid/gid columns: PermAny
+	ar := openFS(t, "testdata/perms.sqlar", sqlarfs.WithPermForUser(12345, 12345))
+	for _, name := range []string{"user/u.txt", "group/g.txt", "others/o.txt"} {
+		if _, err := fs.ReadFile(ar, name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sqlarfs
+
+import (
+	"compress/flate"
+	"context"
+	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+)
+
+// flatePool is a pool of DEFLATE decompressors, which are expensive to allocate.
+type flatePool struct {
+	pool sync.Pool
+
+	reused, allocated atomic.Uint64 // See CacheStats
+}
+
+// flateReader returns a DEFLATE decompressor of r from the pool, with the dictionary
+// set with [WithFlateDictionary]. Close returns it to the pool.
+func (ar *arfs) flateReader(r io.Reader) io.ReadCloser {
+	if fr, ok := ar.flatePool.pool.Get().(io.ReadCloser); ok {
+		if err := fr.(flate.Resetter).Reset(r, ar.flateDict); err == nil {
+			ar.flatePool.reused.Add(1)
+			return &pooledFlateReader{r: fr, pool: &ar.flatePool}
+		}
+	}
+	ar.flatePool.allocated.Add(1)
+	return &pooledFlateReader{r: flate.NewReaderDict(r, ar.flateDict), pool: &ar.flatePool}
+}
+
+// pooledFlateReader returns its decompressor to the pool on Close.
+type pooledFlateReader struct {
+	r    io.ReadCloser // nil once closed
+	pool *flatePool
+}
+
+func (p *pooledFlateReader) Read(b []byte) (int, error) {
+	if p.r == nil {
+		return 0, fs.ErrClosed
+	}
+	return p.r.Read(b)
+}
+
+func (p *pooledFlateReader) Close() error {
+	if p.r == nil {
+		return nil
+	}
+	err := p.r.Close()
+	p.pool.pool.Put(p.r)
+	p.r = nil
+	return err
+}
+
+// OpenPooled returns a reader of the content of regular file name.
+//
+// Closing the reader returns the pooled resources (the DEFLATE decompressor of compressed
+// content, and the slot of [WithMaxConcurrentReads]) for reuse by the next reads.
+// Open manages the same resources with the lifecycle of the file. Connections to the
+// database are not pinned: the data of the file is loaded in memory by a single query.
+// See [CacheFS] to observe the reuse of decompressors.
+func (ar *arfs) OpenPooled(name string) (io.ReadCloser, error) {
+	f, err := ar.OpenContext(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	if _, isDir := f.(*dir); isDir {
+		f.Close()
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.(*file), nil
+}
@@ -0,0 +1,56 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestOpenPooled(t *testing.T) {
+	const N = 50
+	content := bytes.Repeat([]byte("pool "), 1000)
+	compressed := deflate(t, content)
+	db := createDB(t, sqlarSchema)
+	for i := 0; i < N; i++ {
+		insertEntries(t, db, entry{name: strconv.Itoa(i) + ".txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: compressed})
+	}
+	insertEntries(t, db, entry{name: "dir", mode: syscall.S_IFDIR | 0755})
+	ar := newFS(db)
+
+	for i := 0; i < N; i++ {
+		r, err := ar.OpenPooled(strconv.Itoa(i) + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, content) {
+			t.Fatalf("%d: content mismatch", i)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := ar.CacheStats().Decompressors
+	t.Logf("%+v", stats)
+	if stats.Reused+stats.Allocated != N {
+		t.Errorf("got %d decompressors, expected %d", stats.Reused+stats.Allocated, N)
+	}
+	// sync.Pool may drop items (always a few under the race detector)
+	if stats.Reused < N/2 {
+		t.Errorf("decompressors not reused: %+v", stats)
+	}
+
+	if _, err := ar.OpenPooled("dir"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("dir: got %v, expected ErrInvalid", err)
+	}
+	if _, err := ar.OpenPooled("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing: got %v, expected ErrNotExist", err)
+	}
+}
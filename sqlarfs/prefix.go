@@ -0,0 +1,95 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"path"
+	"strings"
+)
+
+// CommonPrefix returns the path of the deepest directory that contains all
+// the entries of the archive, or "" if the entries diverge at the top level
+// (or if the archive is empty).
+//
+// This is useful to detect a top-level wrapper directory (such as "project-1.2.3/")
+// that can be unwrapped with [io/fs.Sub].
+//
+// The prefix is computed with a single query from the lowest and highest names.
+// Permissions are not checked. Hidden entries (see [WithEntryFilter]) are ignored.
+func (ar *arfs) CommonPrefix() (string, error) {
+	var lo, hi sql.NullString
+	var err error
+	if ar.keep != nil {
+		lo, hi, err = ar.nameRangeFiltered()
+	} else {
+		// Sorting on name||'/' ensures that a name is sorted before its descendants
+		// ("a" => "a/", "a/b" => "a/b/") and after its siblings that have it as a
+		// prefix ("a.txt" => "a.txt/").
+		err = ar.db.QueryRow(``+
+			`SELECT MIN(name||'/'),MAX(name||'/')`+
+			` FROM `+ar.table()+
+			` WHERE name NOT IN ('','.')`+
+			` AND `+ar.modeFilter(),
+		).Scan(&lo, &hi)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !lo.Valid {
+		return "", nil
+	}
+	if lo.String == hi.String {
+		// Single entry
+		dir := path.Dir(strings.TrimSuffix(lo.String, "/"))
+		if dir == "." {
+			return "", nil
+		}
+		return dir, nil
+	}
+
+	// The longest common prefix of the lowest and highest names is shared by all names
+	n := 0
+	for n < len(lo.String) && n < len(hi.String) && lo.String[n] == hi.String[n] {
+		n++
+	}
+	i := strings.LastIndexByte(lo.String[:n], '/')
+	if i < 0 {
+		return "", nil
+	}
+	return lo.String[:i], nil
+}
+
+// nameRangeFiltered returns the lowest and highest names (with a '/' suffix) of the entries
+// that are not hidden by the filter set with [WithEntryFilter], computed in Go.
+func (ar *arfs) nameRangeFiltered() (lo, hi sql.NullString, err error) {
+	rows, err := ar.db.Query(`` +
+		`SELECT name,` + ar.modeExpr() +
+		` FROM ` + ar.table() +
+		` WHERE name NOT IN ('','.')` +
+		` AND ` + ar.modeFilter())
+	if err != nil {
+		return lo, hi, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var mode uint32
+		if err := rows.Scan(&name, &mode); err != nil {
+			return lo, hi, err
+		}
+		if ar.hiddenPath(name, mode) {
+			continue
+		}
+		name += "/"
+		if !lo.Valid || name < lo.String {
+			lo = sql.NullString{String: name, Valid: true}
+		}
+		if !hi.Valid || name > hi.String {
+			hi = sql.NullString{String: name, Valid: true}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return lo, hi, err
+	}
+	return lo, hi, rows.Close()
+}
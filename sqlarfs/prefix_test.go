@@ -0,0 +1,70 @@
+package sqlarfs_test
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestCommonPrefix(t *testing.T) {
+	const (
+		dir = syscall.S_IFDIR | 0755
+		reg = syscall.S_IFREG | 0644
+	)
+	for _, tc := range []struct {
+		name    string
+		entries []entry
+		prefix  string
+	}{
+		{"Empty", nil, ""},
+		{"Project", []entry{
+			{name: "project-1.2.3", mode: dir},
+			{name: "project-1.2.3/README", mode: reg},
+			{name: "project-1.2.3/src", mode: dir},
+			{name: "project-1.2.3/src/main.go", mode: reg},
+		}, "project-1.2.3"},
+		{"ImpliedDir", []entry{
+			{name: "project-1.2.3/README", mode: reg},
+			{name: "project-1.2.3/src/main.go", mode: reg},
+		}, "project-1.2.3"},
+		{"Deep", []entry{
+			{name: "a/b/c/d.txt", mode: reg},
+			{name: "a/b/c/e.txt", mode: reg},
+			{name: "a/b/f/g.txt", mode: reg},
+		}, "a/b"},
+		{"Sibling", []entry{
+			{name: "project", mode: dir},
+			{name: "project.txt", mode: reg},
+			{name: "project/README", mode: reg},
+		}, ""},
+		{"SharedStringPrefix", []entry{
+			{name: "project-1/README", mode: reg},
+			{name: "project-2/README", mode: reg},
+		}, ""},
+		{"SingleFile", []entry{
+			{name: "a/b/c.txt", mode: reg},
+		}, "a/b"},
+		{"TopLevelFile", []entry{
+			{name: "c.txt", mode: reg},
+		}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := createDB(t, sqlarSchema)
+			insertEntries(t, db, tc.entries...)
+			prefix, err := newFS(db).CommonPrefix()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prefix != tc.prefix {
+				t.Errorf("got %q, expected %q", prefix, tc.prefix)
+			}
+		})
+	}
+
+	prefix, err := openFS(t, "testdata/dir.sqlar").CommonPrefix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix != "" {
+		t.Errorf("dir.sqlar: got %q", prefix)
+	}
+}
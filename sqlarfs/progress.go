@@ -0,0 +1,58 @@
+package sqlarfs
+
+import "io"
+
+// progressInterval is the number of bytes between calls of the callback of WithReadProgress.
+const progressInterval = 64 * 1024
+
+// WithReadProgress is an [Option] for [New] that sets a callback invoked as the content
+// of a file is read with Open, OpenContext or ReadFileContext (and the functions based on them,
+// such as [fs.ReadFile] or [Extract]), to show the progress of long reads.
+//
+// fn receives the path of the file, the number of bytes of content read so far, and the
+// size of the file. It is called every 64 KiB, and once more with the final count when
+// the end of the content is reached. fn must not block, as it is called from Read.
+func WithReadProgress(fn func(name string, read, total int64)) Option {
+	return optionFunc(func(ar *arfs) {
+		ar.progress = fn
+	})
+}
+
+// progressReader reports the progress of the reads of r, the reader of the content of
+// the file name, whose metadata is info, if a callback is set with WithReadProgress.
+func (ar *arfs) progressReader(name string, info *fileinfo, r io.ReadCloser) io.ReadCloser {
+	if ar.progress == nil {
+		return r
+	}
+	return &progressReadCloser{r: r, fn: ar.progress, path: name, total: info.sz}
+}
+
+// progressReadCloser reports the count of bytes read from r.
+type progressReadCloser struct {
+	r     io.ReadCloser
+	fn    func(name string, read, total int64)
+	path  string
+	total int64
+
+	read     int64
+	reported int64 // -1 once the final count is reported
+}
+
+func (pr *progressReadCloser) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.read += int64(n)
+	switch {
+	case pr.reported < 0:
+	case err == io.EOF:
+		pr.reported = -1
+		pr.fn(pr.path, pr.read, pr.total)
+	case pr.read-pr.reported >= progressInterval:
+		pr.reported = pr.read
+		pr.fn(pr.path, pr.read, pr.total)
+	}
+	return n, err
+}
+
+func (pr *progressReadCloser) Close() error {
+	return pr.r.Close()
+}
@@ -0,0 +1,79 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadProgress(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789abcdef"), 20000) // 320000 bytes
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "large.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(large)), data: deflate(t, large)},
+		entry{name: "small.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("hello")},
+		entry{name: "empty.txt", mode: syscall.S_IFREG | 0644},
+	)
+
+	var mu sync.Mutex
+	calls := make(map[string][]int64)
+	ar := sqlarfs.New(db, sqlarfs.WithReadProgress(func(name string, read, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(calls[name]) == 0 {
+			calls[name] = []int64{total}
+		} else if total != calls[name][0] {
+			t.Errorf("%s: total changed from %d to %d", name, calls[name][0], total)
+		}
+		calls[name] = append(calls[name], read)
+	}))
+
+	for name, size := range map[string]int64{"large.bin": int64(len(large)), "small.txt": 5, "empty.txt": 0} {
+		f, err := ar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Small reads
+		n, err := io.CopyBuffer(io.Discard, struct{ io.Reader }{f}, make([]byte, 1000))
+		f.Close()
+		if err != nil || n != size {
+			t.Fatalf("%s: got %d, %v", name, n, err)
+		}
+
+		c := calls[name]
+		if len(c) < 2 || c[0] != size {
+			t.Fatalf("%s: got calls %v", name, c)
+		}
+		counts := c[1:]
+		for i := 1; i < len(counts); i++ {
+			if counts[i] <= counts[i-1] {
+				t.Errorf("%s: counts not increasing: %v", name, counts)
+				break
+			}
+		}
+		if counts[len(counts)-1] != size {
+			t.Errorf("%s: final count %d, expected %d", name, counts[len(counts)-1], size)
+		}
+		// Bounded frequency
+		if maxCalls := int(size/(64*1024)) + 1; len(counts) > maxCalls {
+			t.Errorf("%s: %d calls, expected at most %d", name, len(counts), maxCalls)
+		}
+	}
+	if len(calls["large.bin"]) < 5 {
+		t.Errorf("large.bin: got calls %v", calls["large.bin"])
+	}
+
+	// fs.ReadFile
+	delete(calls, "small.txt")
+	if _, err := fs.ReadFile(ar, "small.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if c := calls["small.txt"]; len(c) != 2 || c[1] != 5 {
+		t.Errorf("fs.ReadFile: got calls %v", c)
+	}
+}
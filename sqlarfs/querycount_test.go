@@ -0,0 +1,102 @@
+package sqlarfs_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// countingDriver wraps an SQL driver to count the queries.
+type countingDriver struct {
+	driver.Driver
+	queries atomic.Int64
+	last    atomic.Value // Last query prepared
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, d: d}, nil
+}
+
+// countingConn doesn't implement the optional interfaces of [driver.Conn]
+// so every query goes through Prepare.
+type countingConn struct {
+	driver.Conn
+	d *countingDriver
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.queries.Add(1)
+	c.d.last.Store(query)
+	return c.Conn.Prepare(query)
+}
+
+var (
+	countingDriverOnce sync.Once
+	theCountingDriver  *countingDriver
+)
+
+// openCountingDB opens an archive with a driver that counts the queries.
+func openCountingDB(tb testing.TB, path string) (*sql.DB, *countingDriver) {
+	tb.Helper()
+	countingDriverOnce.Do(func() {
+		db, err := sql.Open(sqliteDriver, ":memory:")
+		if err != nil {
+			tb.Fatal(err)
+		}
+		theCountingDriver = &countingDriver{Driver: db.Driver()}
+		db.Close()
+		sql.Register("counting-"+sqliteDriver, theCountingDriver)
+	})
+	db, err := sql.Open("counting-"+sqliteDriver, "file:"+path+"?mode=ro&immutable=1")
+	if err != nil {
+		tb.Fatalf("open %q: %v", path, err)
+	}
+	tb.Cleanup(func() {
+		db.Close()
+	})
+	return db, theCountingDriver
+}
+
+func TestStatTopLevelQueries(t *testing.T) {
+	db, counter := openCountingDB(t, "testdata/dir.sqlar")
+	ar := sqlarfs.New(db)
+	// Load the root
+	if _, err := fs.Stat(ar, "."); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		before := counter.queries.Load()
+		if _, err := fs.Stat(ar, name); err != nil {
+			t.Fatal(err)
+		}
+		if n := counter.queries.Load() - before; n != 1 {
+			t.Errorf("Stat(%q): %d queries, expected 1", name, n)
+		}
+	}
+}
+
+func BenchmarkStatTopLevel(b *testing.B) {
+	db, counter := openCountingDB(b, "testdata/dir.sqlar")
+	ar := sqlarfs.New(db)
+	if _, err := fs.Stat(ar, "."); err != nil {
+		b.Fatal(err)
+	}
+	before := counter.queries.Load()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.Stat(ar, "a.txt"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(counter.queries.Load()-before)/float64(b.N), "queries/op")
+}
@@ -0,0 +1,118 @@
+package sqlarfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// maxRateBurst is the maximum number of bytes that can be read at once without
+// throttling. See WithReadRateLimit.
+const maxRateBurst = 64 * 1024
+
+// WithReadRateLimit is an [Option] for [New] that limits the bandwidth of the reads of the
+// content of files to bytesPerSec, to share the bandwidth of a server among tenants.
+// The limit applies to the decompressed content of the files read with Open, OpenContext
+// and ReadFileContext (and the functions based on them, such as [fs.ReadFile]), and is shared by
+// all those reads (with bursts of at most 64 KiB). ReadFiles and Section are not throttled.
+//
+// A read that is throttled stops waiting when the context of OpenContext (or ReadFileContext)
+// is done, and reports the error of the context.
+func WithReadRateLimit(bytesPerSec int64) Option {
+	if bytesPerSec <= 0 {
+		panic(fmt.Errorf("sqlarfs.WithReadRateLimit: invalid rate"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.readRateLimit = bytesPerSec
+	})
+}
+
+// rateLimiter is a token bucket, in bytes.
+type rateLimiter struct {
+	rate  float64 // Bytes per second
+	burst int
+
+	mu     sync.Mutex
+	tokens float64 // Negative if reads are in advance
+	last   time.Time
+}
+
+func newRateLimiter(bytesPerSec int64) *rateLimiter {
+	burst := maxRateBurst
+	if bytesPerSec < int64(burst) {
+		burst = int(bytesPerSec)
+	}
+	return &rateLimiter{rate: float64(bytesPerSec), burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes n tokens and returns the delay until they are available.
+func (l *rateLimiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait waits until n bytes can be delivered, or until ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	d := l.reserve(n)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitReader throttles r, the reader of the content of name, if a limit is set with WithReadRateLimit.
+func (ar *arfs) rateLimitReader(ctx context.Context, name string, r io.ReadCloser) io.ReadCloser {
+	if ar.rate == nil {
+		return r
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &rateLimitedReader{r: r, l: ar.rate, ctx: ctx, path: name}
+}
+
+// rateLimitedReader throttles the reads of r.
+type rateLimitedReader struct {
+	r    io.ReadCloser
+	l    *rateLimiter
+	ctx  context.Context
+	path string
+}
+
+func (rr *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > rr.l.burst {
+		p = p[:rr.l.burst]
+	}
+	n, err := rr.r.Read(p)
+	if n > 0 {
+		if werr := rr.l.wait(rr.ctx, n); werr != nil {
+			return 0, &fs.PathError{Op: "read", Path: rr.path, Err: werr}
+		}
+	}
+	return n, err
+}
+
+func (rr *rateLimitedReader) Close() error {
+	return rr.r.Close()
+}
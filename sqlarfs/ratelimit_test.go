@@ -0,0 +1,57 @@
+package sqlarfs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadRateLimit(t *testing.T) {
+	const (
+		rate  = 1 << 20
+		burst = 64 << 10
+		size  = rate/2 + burst
+	)
+	content := make([]byte, size)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "large.bin", mode: syscall.S_IFREG | 0644, sz: size, data: deflate(t, content)})
+	ar := newFS(db, sqlarfs.WithReadRateLimit(rate))
+
+	start := time.Now()
+	b, err := fs.ReadFile(ar, "large.bin")
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != size {
+		t.Fatalf("got %d bytes", len(b))
+	}
+	expected := time.Second * (size - burst) / rate
+	t.Logf("elapsed: %v, expected: %v", elapsed, expected)
+	if elapsed < expected*8/10 || elapsed > expected+time.Second {
+		t.Errorf("elapsed: %v, expected: %v", elapsed, expected)
+	}
+
+	// Cancellation while throttled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	f, err := ar.OpenContext(ctx, "large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	start = time.Now()
+	_, err = io.ReadAll(f)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, expected DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > expected/2 {
+		t.Errorf("cancellation took %v", elapsed)
+	}
+}
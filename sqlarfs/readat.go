@@ -0,0 +1,53 @@
+package sqlarfs
+
+import (
+	"io"
+	"io/fs"
+)
+
+// ReadAt implements interface [io.ReaderAt], for concurrent reads of ranges of the content
+// of the file (such as by [net/http.ServeContent] or parsers of zip or PDF files).
+//
+// ReadAt doesn't affect the offset of Read. Calls of ReadAt may run concurrently.
+// If the file is stored uncompressed, each call queries only the requested range
+// of the blob. Otherwise the content is decompressed in memory on the first call.
+// [io.EOF] is returned when the end of the content is reached, even if len(b) bytes are read.
+// The limits of [WithReadRateLimit] and the callback of [WithReadProgress] don't apply.
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrInvalid}
+	}
+	r, err := f.readerAt()
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.ReadAt(b, off)
+	if err == nil && off+int64(n) >= r.Size() {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// readerAt returns the reader used by ReadAt, created on the first call.
+func (f *file) readerAt() (sizedReaderAt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ra != nil {
+		return f.ra, nil
+	}
+	if f.fs == nil { // Closed
+		return nil, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrClosed}
+	}
+	if f.info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrInvalid}
+	}
+	if !f.fs.canRead(f.info.mode) {
+		return nil, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrPermission}
+	}
+	r, err := f.fs.contentReaderAt(f.path, &f.info)
+	if err != nil {
+		return nil, err
+	}
+	f.ra = r
+	return r, nil
+}
@@ -0,0 +1,117 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestFileReadAt(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "deflated.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: deflate(t, content)},
+		entry{name: "empty.bin", mode: syscall.S_IFREG | 0644},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+	)
+	ar := sqlarfs.New(db)
+
+	for _, name := range []string{"stored.bin", "deflated.bin"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := ar.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			ra, ok := f.(io.ReaderAt)
+			if !ok {
+				t.Fatal("io.ReaderAt not implemented")
+			}
+			if err := iotest.TestReader(f, content); err != nil {
+				t.Error(err)
+			}
+
+			// Concurrent range reads
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(off int64) {
+					defer wg.Done()
+					b := make([]byte, 100)
+					n, err := ra.ReadAt(b, off)
+					if err != nil || n != len(b) || !bytes.Equal(b, content[off:off+100]) {
+						t.Errorf("ReadAt(%d): got %d, %v", off, n, err)
+					}
+				}(int64(i) * 1500)
+			}
+			wg.Wait()
+
+			// io.EOF when the end is reached
+			b := make([]byte, 16)
+			if n, err := ra.ReadAt(b, int64(len(content))-16); n != 16 || err != io.EOF {
+				t.Errorf("ReadAt up to the end: got %d, %v, expected 16, io.EOF", n, err)
+			}
+			if n, err := ra.ReadAt(b, int64(len(content))-10); n != 10 || err != io.EOF || !bytes.Equal(b[:n], content[len(content)-10:]) {
+				t.Errorf("ReadAt beyond the end: got %d, %v, expected 10, io.EOF", n, err)
+			}
+			if _, err := ra.ReadAt(b, -1); !errors.Is(err, fs.ErrInvalid) {
+				t.Errorf("ReadAt(-1): got %v, expected ErrInvalid", err)
+			}
+
+			f.Close()
+			if _, err := ra.ReadAt(b, 0); !errors.Is(err, fs.ErrClosed) {
+				t.Errorf("after Close: got %v, expected ErrClosed", err)
+			}
+		})
+	}
+
+	f, err := ar.Open("empty.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := f.(io.ReaderAt).ReadAt(make([]byte, 1), 0); n != 0 || err != io.EOF {
+		t.Errorf("empty.bin: got %d, %v, expected 0, io.EOF", n, err)
+	}
+	f.Close()
+
+	d, err := ar.Open("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if ra, ok := d.(io.ReaderAt); ok {
+		if _, err := ra.ReadAt(make([]byte, 1), 0); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("dir: got %v, expected ErrInvalid", err)
+		}
+	}
+}
+
+func TestFileReadAtTransform(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("abcd")})
+	// The transformed content is longer than sz
+	ar := sqlarfs.New(db, sqlarfs.WithReadTransform(func(_ string, r io.Reader) (io.Reader, error) {
+		b, err := io.ReadAll(r)
+		return bytes.NewReader(bytes.Repeat(b, 3)), err
+	}))
+	f, err := ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b := make([]byte, 4)
+	if n, err := f.(io.ReaderAt).ReadAt(b, 2); n != 4 || err != nil || string(b) != "cdab" {
+		t.Errorf("got %d, %v, %q, expected 4, <nil>, \"cdab\"", n, err, b[:n])
+	}
+	if n, err := f.(io.ReaderAt).ReadAt(b, 8); n != 4 || err != io.EOF || string(b) != "abcd" {
+		t.Errorf("at the end: got %d, %v, %q, expected 4, io.EOF, \"abcd\"", n, err, b[:n])
+	}
+}
@@ -0,0 +1,35 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// ReadFileToBuffer appends the content of regular file name to buf, that the caller
+// can Reset and reuse across calls to avoid allocating a slice for each file.
+// buf is grown using the size of the file as a hint.
+//
+// Errors are the same as with [fs.ReadFile]. On error, buf is left unchanged
+// (except for the partial content of a damaged file, with [WithLenientRead]).
+func (ar *arfs) ReadFileToBuffer(name string, buf *bytes.Buffer) error {
+	f, err := ar.OpenContext(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, isDir := f.(*dir); isDir {
+		return &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	// ReadFrom needs MinRead free bytes to detect the end of the content
+	buf.Grow(ar.contentBufCap(f.(*file).info.sz) + bytes.MinRead)
+	n := buf.Len()
+	if _, err := buf.ReadFrom(f); err != nil {
+		if !(ar.lenientRead && errors.Is(err, ErrPartialContent)) {
+			buf.Truncate(n)
+		}
+		return err
+	}
+	return nil
+}
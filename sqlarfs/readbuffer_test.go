@@ -0,0 +1,80 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadFileToBuffer(t *testing.T) {
+	ar := openFS(t, "testdata/perms.sqlar", sqlarfs.PermOthers)
+	ref := openFS(t, "testdata/perms.sqlar")
+
+	var buf bytes.Buffer
+	buf.WriteString("prefix:")
+	var names []string
+	fs.WalkDir(ref, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if len(names) == 0 {
+		t.Fatal("no files")
+	}
+	for _, name := range names {
+		buf.Truncate(len("prefix:"))
+		expected, errExpected := fs.ReadFile(ar, name)
+		err := ar.ReadFileToBuffer(name, &buf)
+		if (err == nil) != (errExpected == nil) || err != nil && !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("%s: got error %v, expected %v", name, err, errExpected)
+			continue
+		}
+		if err != nil {
+			if buf.String() != "prefix:" {
+				t.Errorf("%s: buffer modified on error", name)
+			}
+			continue
+		}
+		if got := buf.Bytes()[len("prefix:"):]; !bytes.Equal(got, expected) {
+			t.Errorf("%s: got %q, expected %q", name, got, expected)
+		}
+	}
+
+	for _, name := range []string{".", "missing.txt"} {
+		if err := ar.ReadFileToBuffer(name, &buf); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
+
+func benchmarkReadFiles(b *testing.B, read func(ar extFS, name string) error) {
+	const n = 100
+	ar := newFS(createFilesDB(b, n, 4000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := read(ar, fmt.Sprintf("d%d/f%d.txt", i%n%4, i%n)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadFile(b *testing.B) {
+	benchmarkReadFiles(b, func(ar extFS, name string) error {
+		_, err := fs.ReadFile(ar, name)
+		return err
+	})
+}
+
+func BenchmarkReadFileToBuffer(b *testing.B) {
+	var buf bytes.Buffer
+	benchmarkReadFiles(b, func(ar extFS, name string) error {
+		buf.Reset()
+		return ar.ReadFileToBuffer(name, &buf)
+	})
+}
@@ -0,0 +1,66 @@
+package sqlarfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ReadFileContext is like [fs.ReadFile], but bound to ctx: the query of the data runs
+// with ctx, and decompression stops as soon as ctx is done, so that a cancelled request
+// doesn't keep decompressing a large file. The error of ctx is returned wrapped in
+// an [*io/fs.PathError].
+//
+// If the number of concurrent reads is limited (see [WithMaxConcurrentReads]),
+// ctx also bounds the wait for a slot.
+func (ar *arfs) ReadFileContext(ctx context.Context, name string) ([]byte, error) {
+	f, err := ar.OpenContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	file, ok := f.(*file)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if !ar.canRead(file.info.mode) {
+		return nil, &fs.PathError{Op: "read", Path: file.path, Err: fs.ErrPermission}
+	}
+
+	data, sum, err := ar.queryDataContext(ctx, file.path, &file.info)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ar.contentReader(file.path, data, file.info.sz, sum)
+	if err != nil {
+		return nil, err
+	}
+	if file.r, err = ar.transformReader(file.path, r); err != nil {
+		return nil, err
+	}
+	file.r = ar.rateLimitReader(ctx, file.path, file.r)
+	file.r = ar.progressReader(file.path, &file.info, file.r)
+	content, err := readAll(&ctxReader{ctx: ctx, r: file.r, path: file.path}, make([]byte, 0, ar.contentBufCap(file.info.sz)))
+	if err != nil {
+		if ar.lenientRead && errors.Is(err, ErrPartialContent) {
+			return content, err
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+// ctxReader stops reading when ctx is done.
+type ctxReader struct {
+	ctx  context.Context
+	r    io.Reader
+	path string
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, &fs.PathError{Op: "read", Path: r.path, Err: err}
+	}
+	return r.r.Read(p)
+}
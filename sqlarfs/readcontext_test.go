@@ -0,0 +1,82 @@
+package sqlarfs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// countingReader counts the calls to Read and calls hook, if set, after the first one.
+type countingReader struct {
+	r     io.Reader
+	calls int
+	hook  func()
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.calls++
+	if r.calls == 1 && r.hook != nil {
+		defer r.hook()
+	}
+	return r.r.Read(p)
+}
+
+func TestReadFileContext(t *testing.T) {
+	content := make([]byte, 64<<20)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "large.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: deflate(t, content)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var (
+		cr   *countingReader
+		hook func()
+	)
+	ar := newFS(db, sqlarfs.WithReadTransform(func(_ string, r io.Reader) (io.Reader, error) {
+		cr = &countingReader{r: r, hook: hook}
+		return cr, nil
+	}))
+
+	b, err := ar.ReadFileContext(context.Background(), "large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != len(content) {
+		t.Fatalf("got %d bytes, expected %d", len(b), len(content))
+	}
+	total := cr.calls
+	t.Logf("reads: %d", total)
+
+	// Cancelled after the first chunk
+	hook = cancel
+	b, err = ar.ReadFileContext(ctx, "large.bin")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, expected context.Canceled", err)
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "large.bin" {
+		t.Errorf("got %#v, expected *fs.PathError", err)
+	}
+	if b != nil {
+		t.Errorf("got %d bytes on error", len(b))
+	}
+	if cr.calls != 1 || total <= 1 {
+		t.Errorf("decompression not stopped: %d reads of %d", cr.calls, total)
+	}
+
+	// Cancelled before the query
+	if _, err := ar.ReadFileContext(ctx, "large.bin"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, expected context.Canceled", err)
+	}
+
+	for _, name := range []string{".", "missing.bin", "../large.bin"} {
+		if _, err := ar.ReadFileContext(context.Background(), name); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
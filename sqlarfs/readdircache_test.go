@@ -0,0 +1,35 @@
+package sqlarfs_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestReadDirCachesDirs checks that Stat finds the directories listed by ReadDir
+// in the cache, both explicit and implied by the paths of their content.
+func TestReadDirCachesDirs(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/sub", mode: syscall.S_IFDIR | 0755},
+		entry{name: "implied/a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+	)
+	ar := sqlarfs.New(db)
+	for _, dir := range []string{".", "dir"} {
+		if _, err := ar.ReadDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without the cache, the directories are not found anymore
+	if _, err := db.Exec(`DELETE FROM sqlar`); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dir", "dir/sub", "implied"} {
+		if _, err := ar.Stat(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package sqlarfs_test
+
+import (
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestReadDirRowsError checks that an error that interrupts the listing is reported.
+func TestReadDirRowsError(t *testing.T) {
+	db := createDB(t,
+		`CREATE TABLE entries(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB)`,
+		// abs() fails with an integer overflow on the row "boom"
+		`CREATE VIEW sqlar AS SELECT name,CASE name WHEN 'boom' THEN abs(-9223372036854775807-1) ELSE mode END AS mode,mtime,sz,data FROM entries`,
+		`INSERT INTO entries VALUES('a.txt',33188,0,1,'a'),('boom',33188,0,1,'b'),('c.txt',33188,0,1,'c')`,
+	)
+	ar := sqlarfs.New(db)
+	if entries, err := ar.ReadDir("."); err == nil {
+		t.Errorf("error expected, got %d entries", len(entries))
+	}
+}
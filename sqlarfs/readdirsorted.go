@@ -0,0 +1,64 @@
+package sqlarfs
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// SortKey is the attribute of entries used to sort the result of [ListFS].
+type SortKey int
+
+const (
+	SortKeyName    SortKey = iota // The name of the entry
+	SortKeySize                   // The size of the entry
+	SortKeyModTime                // The modification time of the entry
+)
+
+func (k SortKey) String() string {
+	switch k {
+	case SortKeyName:
+		return "name"
+	case SortKeySize:
+		return "size"
+	case SortKeyModTime:
+		return "modtime"
+	default:
+		return fmt.Sprintf("SortKey(%d)", int(k))
+	}
+}
+
+// ReadDirSorted is like ReadDir, but the entries are sorted by the attribute by, in
+// descending order if desc is true, for user interfaces that offer "sort by size/date".
+// The sort is done by SQLite, the same way whatever the driver.
+//
+// Entries with the same size or modification time are sorted by name (ascending).
+// Names are compared byte per byte, as with ReadDir.
+// Directories that are only implied by the paths of their content have size 0 and
+// modification time 0 (1970-01-01 UTC): they come first when sorting by size or time
+// in ascending order, and last in descending order.
+func (ar *arfs) ReadDirSorted(dir string, by SortKey, desc bool) ([]fs.DirEntry, error) {
+	var orderBy string
+	switch by {
+	case SortKeyName:
+		orderBy = ` ORDER BY n`
+	case SortKeySize:
+		orderBy = ` ORDER BY s`
+	case SortKeyModTime:
+		orderBy = ` ORDER BY t`
+	default:
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrInvalid}
+	}
+	if desc {
+		orderBy += ` DESC`
+	}
+	if by != SortKeyName {
+		orderBy += `,n`
+	}
+
+	dir = ar.cleanPath(dir)
+	list, err := ar.readDirOrder(dir, orderBy)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: err}
+	}
+	return list, nil
+}
@@ -0,0 +1,85 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadDirSorted(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+	for _, tc := range []struct {
+		dir      string
+		by       sqlarfs.SortKey
+		desc     bool
+		expected string
+	}{
+		{".", sqlarfs.SortKeyName, false, "a.txt b.txt subdir"},
+		{".", sqlarfs.SortKeyName, true, "subdir b.txt a.txt"},
+		{".", sqlarfs.SortKeySize, false, "subdir a.txt b.txt"},
+		{".", sqlarfs.SortKeySize, true, "a.txt b.txt subdir"},
+		{".", sqlarfs.SortKeyModTime, false, "subdir a.txt b.txt"},
+		{".", sqlarfs.SortKeyModTime, true, "b.txt a.txt subdir"},
+		{"subdir", sqlarfs.SortKeyModTime, true, "d.txt c.txt subdir2"},
+		{"subdir/subdir2", sqlarfs.SortKeyName, true, "f.txt e.txt"},
+	} {
+		entries, err := ar.ReadDirSorted(tc.dir, tc.by, tc.desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(entries); got != tc.expected {
+			t.Errorf("%s by %v (desc: %t): got %q, expected %q", tc.dir, tc.by, tc.desc, got, tc.expected)
+		}
+	}
+
+	if _, err := ar.ReadDirSorted(".", sqlarfs.SortKey(42), false); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("invalid key: got %v, expected ErrInvalid", err)
+	}
+	if _, err := ar.ReadDirSorted("a.txt", sqlarfs.SortKeyName, false); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("file: got %v, expected ErrInvalid", err)
+	}
+}
+
+func TestReadDirSortedImplied(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "big.bin", mode: syscall.S_IFREG | 0644, mtime: 100, sz: 3, data: []byte("big")},
+		entry{name: "small.bin", mode: syscall.S_IFREG | 0644, mtime: 300, sz: 1, data: []byte("s")},
+		entry{name: "implied/x.txt", mode: syscall.S_IFREG | 0644, mtime: 500, sz: 1, data: []byte("x")},
+		// An explicit directory wins over the directory implied by its content
+		entry{name: "explicit", mode: syscall.S_IFDIR | 0755, mtime: 200},
+		entry{name: "explicit/y.txt", mode: syscall.S_IFREG | 0644, mtime: 500, sz: 1, data: []byte("y")},
+		// A file wins over the directory implied by the paths of other entries
+		entry{name: "shadow", mode: syscall.S_IFREG | 0644, mtime: 400, sz: 2, data: []byte("sh")},
+		entry{name: "shadow/z.txt", mode: syscall.S_IFREG | 0644, mtime: 500, sz: 1, data: []byte("z")},
+	)
+	ar := newFS(db)
+
+	for _, tc := range []struct {
+		by       sqlarfs.SortKey
+		desc     bool
+		expected string
+	}{
+		{sqlarfs.SortKeyName, false, "big.bin explicit implied shadow small.bin"},
+		{sqlarfs.SortKeySize, false, "explicit implied small.bin shadow big.bin"},
+		{sqlarfs.SortKeySize, true, "big.bin shadow small.bin explicit implied"},
+		{sqlarfs.SortKeyModTime, false, "implied big.bin explicit small.bin shadow"},
+		{sqlarfs.SortKeyModTime, true, "shadow small.bin explicit big.bin implied"},
+	} {
+		entries, err := ar.ReadDirSorted(".", tc.by, tc.desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(entries); got != tc.expected {
+			t.Errorf("by %v (desc: %t): got %q, expected %q", tc.by, tc.desc, got, tc.expected)
+		}
+		for _, e := range entries {
+			if e.Name() == "shadow" && e.IsDir() {
+				t.Error("shadow: got directory, expected file")
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sqlarfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// ErrNoMemoryDriver is returned by [OpenReaderAt] when none of the registered
+// [database/sql] drivers is an SQLite driver able to load a database from memory.
+var ErrNoMemoryDriver = errors.New("sqlarfs: no registered SQLite driver supports in-memory databases")
+
+// memDrivers are the names under which the SQLite drivers known to support
+// deserialization register themselves.
+var memDrivers = []string{
+	"sqlite3", // github.com/mattn/go-sqlite3
+	"sqlite",  // modernc.org/sqlite
+}
+
+// memDBCount is used to build unique names of in-memory databases.
+var memDBCount atomic.Uint64
+
+// OpenReaderAt opens an SQLite Archive File whose content is available from r (size bytes),
+// without writing a temporary file. This is useful for archives fetched over the network
+// or embedded in the program with [embed].
+//
+// The archive is loaded in memory using the first registered [database/sql] driver
+// among [github.com/mattn/sqlite3] ("sqlite3") and [modernc.org/sqlite] ("sqlite"):
+// other drivers, including those that wrap an SQLite driver (for logging or tracing),
+// are not used. [ErrNoMemoryDriver] is returned if none of them is registered.
+//
+// The returned [io.Closer] must be called to release the memory when the FS is not used anymore.
+func OpenReaderAt(r io.ReaderAt, size int64, opts ...Option) (FS, io.Closer, error) {
+	if size < 0 {
+		return nil, nil, fmt.Errorf("sqlarfs.OpenReaderAt: invalid size %d", size)
+	}
+	data, err := readAll(io.NewSectionReader(r, 0, size), make([]byte, 0, bufCap(size)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+
+	registered := sql.Drivers() // Sorted
+	for _, driverName := range memDrivers {
+		if i := sort.SearchStrings(registered, driverName); i == len(registered) || registered[i] != driverName {
+			continue
+		}
+		db, err := openMemDB(driverName, data)
+		if err != nil {
+			if errors.Is(err, ErrNoMemoryDriver) {
+				continue
+			}
+			return nil, nil, err
+		}
+		ar := New(db.DB, opts...)
+		if err := ar.(*arfs).checkSchema(); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return ar, db, nil
+	}
+	return nil, nil, ErrNoMemoryDriver
+}
+
+// memDB is an in-memory database shared by all the connections of db.
+type memDB struct {
+	*sql.DB
+	pin *sql.Conn // Keeps the database alive
+}
+
+func (m *memDB) Close() error {
+	err := m.pin.Close()
+	if err2 := m.DB.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// openMemDB loads the SQLite database serialized in data into an in-memory database.
+//
+// SQLite's deserialization gives a database private to a single connection, so it is
+// copied to a named database of the "memdb" VFS that can be shared by all the
+// connections of the [database/sql] pool.
+func openMemDB(driverName string, data []byte) (*memDB, error) {
+	ctx := context.Background()
+
+	tmp, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		return nil, ErrNoMemoryDriver
+	}
+	defer tmp.Close()
+	conn, err := tmp.Conn(ctx)
+	if err != nil {
+		return nil, ErrNoMemoryDriver
+	}
+	defer conn.Close()
+	err = conn.Raw(func(driverConn any) error {
+		switch c := driverConn.(type) {
+		case interface {
+			Deserialize(b []byte, schema string) error // github.com/mattn/go-sqlite3
+		}:
+			return c.Deserialize(data, "main")
+		case interface {
+			Deserialize(b []byte) error // modernc.org/sqlite
+		}:
+			return c.Deserialize(data)
+		default: // Not an SQLite driver, or the connection is wrapped
+			return ErrNoMemoryDriver
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("file:/sqlarfs-%d?vfs=memdb", memDBCount.Add(1))
+	db, err := sql.Open(driverName, name)
+	if err != nil {
+		return nil, err
+	}
+	pin, err := db.Conn(ctx)
+	if err == nil {
+		// The shared database is created by the first connection
+		_, err = pin.ExecContext(ctx, `SELECT 1`)
+		if err == nil {
+			_, err = conn.ExecContext(ctx, `VACUUM INTO ?`, name)
+		}
+		if err != nil {
+			pin.Close()
+		}
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &memDB{DB: db, pin: pin}, nil
+}
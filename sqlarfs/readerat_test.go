@@ -0,0 +1,94 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+//go:embed testdata/simple.sqlar
+var simpleSqlar []byte
+
+func TestOpenReaderAt(t *testing.T) {
+	ar, closer, err := sqlarfs.OpenReaderAt(bytes.NewReader(simpleSqlar), int64(len(simpleSqlar)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := closer.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if err := fstest.TestFS(ar, "foo.txt", "bar.txt"); err != nil {
+		t.Error(err)
+	}
+
+	expected, err := fs.ReadFile(openFS(t, "testdata/simple.sqlar"), "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fs.ReadFile(ar, "foo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if _, _, err := sqlarfs.OpenReaderAt(bytes.NewReader(simpleSqlar), 10); err == nil {
+		t.Error("truncated archive: error expected")
+	}
+	for _, size := range []int64{-1, 1 << 62} {
+		if _, _, err := sqlarfs.OpenReaderAt(bytes.NewReader(simpleSqlar), size); err == nil {
+			t.Errorf("size %d: error expected", size)
+		}
+	}
+}
+
+// panicDriver is a [database/sql/driver.Driver] that must not be used.
+type panicDriver struct{}
+
+func (panicDriver) Open(name string) (driver.Conn, error) {
+	panic("panicDriver: unexpected Open")
+}
+
+// TestOpenReaderAtOtherDriver checks that OpenReaderAt doesn't probe the drivers that are not SQLite drivers.
+func TestOpenReaderAtOtherDriver(t *testing.T) {
+	sql.Register("sqlarfs-test-panic", panicDriver{})
+	_, closer, err := sqlarfs.OpenReaderAt(bytes.NewReader(simpleSqlar), int64(len(simpleSqlar)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	closer.Close()
+}
+
+// TestWrappedDriver checks that an archive can be read through a driver wrapped by a middleware
+// that hides the connection of the SQLite driver.
+func TestWrappedDriver(t *testing.T) {
+	db, counter := openCountingDB(t, "testdata/simple.sqlar")
+	ar := sqlarfs.New(db)
+	if err := fstest.TestFS(ar, "foo.txt", "bar.txt"); err != nil {
+		t.Error(err)
+	}
+	if counter.queries.Load() == 0 {
+		t.Error("queries did not go through the wrapping driver")
+	}
+
+	// The wrapping driver is registered, but can't load an archive in memory:
+	// OpenReaderAt falls back to the other drivers.
+	ar, closer, err := sqlarfs.OpenReaderAt(bytes.NewReader(simpleSqlar), int64(len(simpleSqlar)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	if err := fstest.TestFS(ar, "foo.txt", "bar.txt"); err != nil {
+		t.Error(err)
+	}
+}
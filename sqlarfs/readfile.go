@@ -0,0 +1,100 @@
+package sqlarfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+)
+
+// ReadFile implements interface [fs.ReadFileFS]: [fs.ReadFile] fetches the metadata and the
+// data of the file with a single query (once the parent directories are known to be traversable),
+// instead of a query for Open and another one for Read.
+//
+// Errors are the same as with Open then Read, except for directories: [fs.ErrInvalid]
+// is reported, as with ReadFileToBuffer. If the reads are limited or observed
+// ([WithMaxConcurrentReads], [WithReadRateLimit], [WithReadProgress]),
+// the file is read like with Open.
+func (ar *arfs) ReadFile(name string) ([]byte, error) {
+	if ar.reads != nil || ar.rate != nil || ar.progress != nil || ar.dirOnly(name) {
+		return ar.ReadFileContext(context.Background(), name)
+	}
+
+	name = ar.cleanPath(name)
+	if name == "." {
+		if _, err := ar.statRoot(); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, err := ar.traverseParent(name); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if ar.negativeCacheTTL > 0 && ar.notExist.has(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	hashCol, err := ar.hashColumn()
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	var (
+		mode uint32
+		sz   int64
+		data []byte
+		sum  []byte
+	)
+	err = ar.db.QueryRow(``+
+		`SELECT `+ar.modeExpr()+`,sz,data,`+hashCol+
+		` FROM `+ar.table()+
+		` WHERE name=?`+ar.collate()+
+		` AND `+ar.modeFilter()+ // Skip file with broken mode
+		` LIMIT 1`,
+		name,
+	).Scan(&mode, &sz, &data, &sum)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		// Directory implied by the paths of its content, or missing file
+		info, err := ar.stat(name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		mode = info.mode
+	default:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if ar.hidden(name, mode) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if isDirMode(mode) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if !ar.canRead(mode) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrPermission}
+	}
+
+	data, err = ar.decodeData(name, data)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ar.contentReader(name, data, sz, sum)
+	if err != nil {
+		return nil, err
+	}
+	if r, err = ar.transformReader(name, r); err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	content, err := readAll(r, make([]byte, 0, ar.contentBufCap(sz)))
+	if err != nil {
+		if ar.lenientRead && errors.Is(err, ErrPartialContent) {
+			return content, err
+		}
+		return nil, err
+	}
+	return content, nil
+}
@@ -0,0 +1,104 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadFile(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 5, data: []byte("hello")},
+		entry{name: "deflated.txt", mode: syscall.S_IFREG | 0644, sz: 11, data: deflate(t, []byte("hello world"))},
+		entry{name: "private.txt", mode: syscall.S_IFREG | 0600, sz: 6, data: []byte("secret")},
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755},
+		entry{name: "dir/b.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("b")},
+		entry{name: "implied/c.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("c")},
+		entry{name: "closed", mode: syscall.S_IFDIR | 0700},
+		entry{name: "closed/d.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("d")},
+	)
+	ar := newFS(db, sqlarfs.PermOthers)
+	if _, ok := ar.(fs.ReadFileFS); !ok {
+		t.Fatal("fs.ReadFileFS not implemented")
+	}
+
+	// Same result as Open then Read
+	for _, name := range []string{
+		"a.txt", "deflated.txt", "dir/b.txt", "implied/c.txt",
+		"private.txt", "closed/d.txt", "missing.txt", "dir/missing.txt", "a.txt/x",
+		"/a.txt", "../a.txt",
+	} {
+		got, err := ar.ReadFile(name)
+		expected, expectedErr := readByOpen(ar, name)
+		if string(got) != string(expected) {
+			t.Errorf("%s: got %q, expected %q", name, got, expected)
+		}
+		if (err == nil) != (expectedErr == nil) {
+			t.Errorf("%s: got error %v, expected %v", name, err, expectedErr)
+			continue
+		}
+		if err != nil {
+			var pathErr *fs.PathError
+			if !errors.As(err, &pathErr) {
+				t.Errorf("%s: got %#v, expected *fs.PathError", name, err)
+			}
+			for _, target := range []error{fs.ErrNotExist, fs.ErrPermission, fs.ErrInvalid} {
+				if errors.Is(err, target) != errors.Is(expectedErr, target) {
+					t.Errorf("%s: got error %v, expected %v", name, err, expectedErr)
+				}
+			}
+		}
+	}
+
+	// Directories, as with ReadFileToBuffer (Read reports ErrNotExist)
+	for _, name := range []string{".", "dir", "implied"} {
+		if _, err := ar.ReadFile(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("%s: got %v, expected ErrInvalid", name, err)
+		}
+	}
+
+	// A single query
+	countingDB, counter := openCountingDB(t, "testdata/simple.sqlar")
+	simple := newFS(countingDB)
+	if _, err := simple.Stat("."); err != nil { // Load the root
+		t.Fatal(err)
+	}
+	before := counter.queries.Load()
+	if b, err := fs.ReadFile(simple, "foo.txt"); err != nil || string(b) != "Foo\n" {
+		t.Fatalf("got %q, %v", b, err)
+	}
+	if n := counter.queries.Load() - before; n != 1 {
+		t.Errorf("%d queries, expected 1", n)
+	}
+
+	if err := fstest.TestFS(openFS(t, "testdata/dir.sqlar"), "a.txt", "subdir/subdir2/e.txt"); err != nil {
+		t.Error(err)
+	}
+}
+
+// readByOpen reads file name without using ReadFile.
+func readByOpen(ar fs.FS, name string) ([]byte, error) {
+	f, err := ar.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func TestReadFileHugeSize(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "huge.txt", mode: syscall.S_IFREG | 0644, sz: 1 << 62, data: deflate(t, []byte("hello"))},
+	)
+	for _, opts := range [][]sqlarfs.Option{nil, {sqlarfs.WithMaxDecompressBytes(1 << 20)}} {
+		// Only check that reading doesn't panic
+		fs.ReadFile(newFS(db, opts...), "huge.txt")
+	}
+}
@@ -0,0 +1,174 @@
+package sqlarfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// readFilesChunk is the maximum number of names per query in ReadFiles.
+// This is below the default limit of 999 parameters of old SQLite versions.
+const readFilesChunk = 500
+
+// MissingFilesError is returned by ReadFiles when some of the requested files do not exist.
+type MissingFilesError struct {
+	Names []string
+}
+
+func (e *MissingFilesError) Error() string {
+	return "sqlarfs: files not found: " + strings.Join(e.Names, ", ")
+}
+
+// Unwrap returns [fs.ErrNotExist].
+func (e *MissingFilesError) Unwrap() error {
+	return fs.ErrNotExist
+}
+
+// ReadFiles reads the content of multiple regular files, using a few queries.
+// This is intended for loading sets of small files (templates, translations...).
+//
+// If some files do not exist, the content of the others is returned
+// with a [*MissingFilesError].
+func (ar *arfs) ReadFiles(names []string) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(names))
+	var missing []string
+	pending := make(map[string]bool, len(names))
+	var query []string
+	for _, name := range names {
+		if !fs.ValidPath(name) || name == "." {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+		}
+		if pending[name] {
+			continue
+		}
+		if _, err := ar.traverseParent(name); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		pending[name] = true
+		query = append(query, name)
+	}
+
+	for len(query) > 0 {
+		chunk := query
+		if len(chunk) > readFilesChunk {
+			chunk = chunk[:readFilesChunk]
+		}
+		query = query[len(chunk):]
+		if err := ar.readFiles(chunk, files); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range names {
+		if pending[name] && files[name] == nil {
+			missing = append(missing, name)
+			pending[name] = false
+		}
+	}
+	if missing != nil {
+		return files, &MissingFilesError{Names: missing}
+	}
+	return files, nil
+}
+
+func (ar *arfs) readFiles(names []string, files map[string][]byte) error {
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	hashCol, err := ar.hashColumn()
+	if err != nil {
+		return err
+	}
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+`,sz,data,`+hashCol+
+		` FROM `+ar.table()+
+		` WHERE name IN (?`+strings.Repeat(",?", len(names)-1)+`)`+
+		` AND `+ar.modeFilterReg(),
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name string
+			mode uint32
+			sz   int64
+			data []byte
+			sum  []byte
+		)
+		if err := rows.Scan(&name, &mode, &sz, &data, &sum); err != nil {
+			return err
+		}
+		if ar.hidden(name, mode) {
+			continue // Reported as missing
+		}
+		if !ar.canRead(mode) {
+			return &fs.PathError{Op: "read", Path: name, Err: fs.ErrPermission}
+		}
+		data, err := ar.decodeData(name, data)
+		if err != nil {
+			return err
+		}
+		r, err := ar.contentReader(name, data, sz, sum)
+		if err != nil {
+			return err
+		}
+		if r, err = ar.transformReader(name, r); err != nil {
+			return err
+		}
+		content := make([]byte, 0, ar.contentBufCap(sz))
+		content, err = readAll(r, content)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		files[name] = content
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// readAll is like [io.ReadAll] but appends to buf.
+func readAll(r io.Reader, buf []byte) ([]byte, error) {
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return buf, err
+		}
+	}
+}
+
+// maxBufCap is the maximum capacity of the buffers allocated upfront to read the content of a file.
+const maxBufCap = 512 * 1024
+
+// bufCap returns the capacity of the buffer to allocate upfront to read the content of a file
+// of sz bytes. As sz comes from the archive, that may be untrusted, the capacity is bounded:
+// readAll grows the buffer as needed.
+func bufCap(sz int64) int {
+	return int(max(0, min(sz, maxBufCap)))
+}
+
+// contentBufCap is like bufCap, but also bounded by the limit set with [WithMaxDecompressBytes].
+func (ar *arfs) contentBufCap(sz int64) int {
+	if ar.maxDecompressBytes > 0 {
+		sz = min(sz, ar.maxDecompressBytes)
+	}
+	return bufCap(sz)
+}
@@ -0,0 +1,86 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadFiles(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar", sqlarfs.PermOwner)
+
+	names := []string{"a.txt", "subdir/c.txt", "subdir/subdir2/f.txt", "a.txt"}
+	files, err := ar.ReadFiles(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("got %d files", len(files))
+	}
+	for _, name := range names {
+		expected, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(files[name]) != string(expected) {
+			t.Errorf("%s: got %q, expected %q", name, files[name], expected)
+		}
+	}
+
+	files, err = ar.ReadFiles([]string{"a.txt", "missing.txt", "subdir", "missing/x.txt"})
+	var missingErr *sqlarfs.MissingFilesError
+	if !errors.As(err, &missingErr) || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, expected *MissingFilesError", err)
+	}
+	t.Log(err)
+	if len(missingErr.Names) != 3 {
+		t.Errorf("got %q", missingErr.Names)
+	}
+	if string(files["a.txt"]) != "a\n" {
+		t.Errorf("a.txt: got %q", files["a.txt"])
+	}
+
+	ar = openFS(t, "testdata/perms.sqlar", sqlarfs.PermOthers)
+	if _, err := ar.ReadFiles([]string{"others/o.txt", "user/u.txt"}); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("got %v, expected fs.ErrPermission", err)
+	}
+}
+
+// TestHugeSize checks that the size recorded in the archive, that may be untrusted,
+// is not used to allocate memory upfront.
+func TestHugeSize(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "huge.txt", mode: syscall.S_IFREG | 0644, sz: 1 << 62, data: deflate(t, []byte("hello"))},
+		entry{name: "negative.txt", mode: syscall.S_IFREG | 0644, sz: -1, data: []byte("hello")},
+	)
+	for _, opts := range [][]sqlarfs.Option{nil, {sqlarfs.WithMaxDecompressBytes(1 << 20)}} {
+		ar := newFS(db, opts...)
+		for _, name := range []string{"huge.txt", "negative.txt"} {
+			// Only check that reading doesn't panic
+			ar.ReadFiles([]string{name})
+			ar.ReadFileContext(context.Background(), name)
+			ar.ReadFileHash(name, sha256.New())
+			ar.ReadFileToBuffer(name, new(bytes.Buffer))
+			ar.Section(name, 0, 0)
+			ar.EstimateCompression(name)
+			f, err := sqlarfs.WithCache(ar, &mapCache{}, "").Open(name)
+			if err == nil {
+				io.ReadAll(f)
+				f.Close()
+			}
+			sqlarfs.ServeFile(httptest.NewRecorder(), httptest.NewRequest("GET", "/"+name, nil), ar, name)
+		}
+	}
+	if _, err := sqlarfs.Optimize(db); err != nil {
+		t.Error(err)
+	}
+}
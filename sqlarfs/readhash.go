@@ -0,0 +1,31 @@
+package sqlarfs
+
+import (
+	"context"
+	"hash"
+	"io"
+	"io/fs"
+)
+
+// ReadFileHash reads the content of regular file name like [fs.ReadFile], writing it also
+// to h while it is decompressed, so that the caller gets the digest of the content
+// with h.Sum(nil) without a second pass over the data. Any [hash.Hash] can be used
+// (ex: sha256, crc32).
+//
+// h is not reset: on error, its state is undefined.
+func (ar *arfs) ReadFileHash(name string, h hash.Hash) ([]byte, error) {
+	f, err := ar.OpenContext(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, isDir := f.(*dir); isDir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	// hash.Hash.Write never returns an error
+	data, err := readAll(io.TeeReader(f, h), make([]byte, 0, ar.contentBufCap(f.(*file).info.sz)))
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,59 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash/crc32"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadFileHash(t *testing.T) {
+	ar := openFS(t, "testdata/perms.sqlar", sqlarfs.PermOthers)
+	ref := openFS(t, "testdata/perms.sqlar")
+
+	var names []string
+	fs.WalkDir(ref, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if len(names) == 0 {
+		t.Fatal("no files")
+	}
+	for _, name := range names {
+		expected, errExpected := fs.ReadFile(ar, name)
+		h := sha256.New()
+		data, err := ar.ReadFileHash(name, h)
+		if (err == nil) != (errExpected == nil) || err != nil && !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("%s: got error %v, expected %v", name, err, errExpected)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("%s: got %q, expected %q", name, data, expected)
+		}
+		if sum := sha256.Sum256(expected); !bytes.Equal(h.Sum(nil), sum[:]) {
+			t.Errorf("%s: sha256 mismatch", name)
+		}
+
+		c := crc32.NewIEEE()
+		if _, err := ar.ReadFileHash(name, c); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if c.Sum32() != crc32.ChecksumIEEE(expected) {
+			t.Errorf("%s: crc32 mismatch", name)
+		}
+	}
+
+	for _, name := range []string{".", "missing.txt"} {
+		if _, err := ar.ReadFileHash(name, sha256.New()); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package sqlarfs
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithMaxConcurrentReads is an [Option] for [New] that limits to n the number of
+// regular files that can be open simultaneously, to bound the memory used for
+// file content (which is loaded entirely in memory on first read).
+//
+// When the limit is reached, Open blocks until a file is closed. Use OpenContext
+// to bound the wait. Beware of deadlocks: a goroutine that keeps files open
+// while opening more files may block forever.
+func WithMaxConcurrentReads(n int) Option {
+	if n <= 0 {
+		panic(fmt.Errorf("sqlarfs.WithMaxConcurrentReads: invalid limit"))
+	}
+	return optionFunc(func(ar *arfs) {
+		ar.maxConcurrentReads = n
+	})
+}
+
+// acquireRead waits for a slot of the semaphore of concurrent reads for f.
+func (ar *arfs) acquireRead(ctx context.Context, f *file) error {
+	if ar.reads == nil {
+		return nil
+	}
+	select {
+	case ar.reads <- struct{}{}:
+		f.slot = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseRead releases a slot of the semaphore of concurrent reads.
+func (ar *arfs) releaseRead() {
+	<-ar.reads
+}
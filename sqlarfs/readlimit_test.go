@@ -0,0 +1,65 @@
+package sqlarfs_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestMaxConcurrentReads(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar", sqlarfs.WithMaxConcurrentReads(2))
+
+	f1, err := ar.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := ar.Open("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories don't use a slot
+	d, err := ar.Open("subdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ar.OpenContext(ctx, "subdir/c.txt"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, expected context.DeadlineExceeded", err)
+	}
+
+	opened := make(chan fs.File)
+	go func() {
+		f3, err := ar.Open("subdir/c.txt")
+		if err != nil {
+			t.Error(err)
+		}
+		opened <- f3
+	}()
+
+	select {
+	case <-opened:
+		t.Fatal("third Open didn't block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	f1.Close()
+	f1.Close() // Closing twice must not release twice
+
+	select {
+	case f3 := <-opened:
+		if f3 != nil {
+			f3.Close()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("third Open still blocked after Close")
+	}
+	f2.Close()
+}
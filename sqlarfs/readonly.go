@@ -0,0 +1,21 @@
+package sqlarfs
+
+// WithReadOnlyGuarantee is an [Option] for [New] by which the caller promises
+// that the sqlar table will not be modified during the lifetime of the [FS],
+// even if the database handle is read-write.
+//
+// This enables caching of the metadata of regular files, in addition to the
+// metadata of directories, as is safe with a database opened in read-only,
+// immutable mode.
+//
+// If the promise is broken, the behavior is undefined: stale metadata may be
+// returned and reads of modified files may fail.
+//
+// Without the promise, the read of the content of a file checks that its size and
+// modification time are still those seen when it was opened, and fails with [ErrChanged]
+// if the file has been replaced in the meantime.
+func WithReadOnlyGuarantee() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.readOnly = true
+	})
+}
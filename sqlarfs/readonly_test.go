@@ -0,0 +1,95 @@
+package sqlarfs_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestReadOnlyGuarantee(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")})
+
+	ar := sqlarfs.New(db)
+	arRO := sqlarfs.New(db, sqlarfs.WithReadOnlyGuarantee())
+	for _, fsys := range []sqlarfs.FS{ar, arRO} {
+		if _, err := fsys.Stat("a.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Break the promise to check that the file metadata is cached
+	if _, err := db.Exec(`UPDATE sqlar SET sz=2, data='ab' WHERE name='a.txt'`); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ar.Stat("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 2 {
+		t.Errorf("no guarantee: got size %d, expected 2", info.Size())
+	}
+
+	info, err = arRO.Stat("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 1 {
+		t.Errorf("read-only guarantee: got size %d, expected 1 (cached)", info.Size())
+	}
+}
+
+func TestReadOnlyGuaranteeReadDir(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "dir/a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")})
+
+	ar := sqlarfs.New(db, sqlarfs.WithReadOnlyGuarantee())
+	if _, err := ar.ReadDir("dir"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Break the promise to check that the file metadata listed by ReadDir is cached
+	if _, err := db.Exec(`DELETE FROM sqlar`); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"dir", "dir/a.txt"} {
+		if _, err := ar.Stat(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// BenchmarkListThenOpen benchmarks the common pattern of listing a directory then opening each of its files.
+func BenchmarkListThenOpen(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		opts []sqlarfs.Option
+	}{
+		{"default", nil},
+		{"read-only", []sqlarfs.Option{sqlarfs.WithReadOnlyGuarantee()}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			db := openDB(b, "testdata/dir.sqlar")
+			for i := 0; i < b.N; i++ {
+				ar := sqlarfs.New(db, tc.opts...)
+				entries, err := ar.ReadDir("subdir")
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, e := range entries {
+					if e.IsDir() {
+						continue
+					}
+					f, err := ar.Open("subdir/" + e.Name())
+					if err != nil {
+						b.Fatal(err)
+					}
+					f.Close()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"strconv"
+)
+
+// Cache is an external store of the content of files for [WithCache], such as Redis or a disk cache.
+// Its methods may be called concurrently.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, data []byte)
+}
+
+// WithCache returns an [FS] that consults cache before reading the content of regular files
+// from ar, for archives stored on slow storage (ex: a network VFS). The content read from
+// ar is stored in cache once a file has been read completely.
+//
+// Keys are derived from namespace, the path, the modification time and the size of a file,
+// so a modified file gets a new key. namespace must identify the archive and the options of
+// [New] that change the content read from it (such as [WithReadTransform]), to share
+// cache between several archives or configurations.
+// The metadata is still read from ar: use [WithReadOnlyGuarantee]
+// to read files from the cache without querying the database.
+//
+// The permission mask of ar applies to the content read from the cache. As it can only
+// be checked without reading the content by an FS returned by [New], the cache is not
+// used with other implementations of [FS].
+//
+// Only Open, OpenContext, ReadFile (and thus [fs.ReadFile]) and ReadFileContext consult the cache: Stat and ReadDir
+// are those of ar. The returned FS implements [ContextFS] and [io/fs.ReadFileFS], but not the
+// other optional interfaces of ar.
+func WithCache(ar FS, cache Cache, namespace string) FS {
+	return &cachedFS{FS: ar, cache: cache, namespace: namespace}
+}
+
+type cachedFS struct {
+	FS
+	cache     Cache
+	namespace string
+}
+
+var (
+	_ ContextFS     = (*cachedFS)(nil)
+	_ fs.ReadFileFS = (*cachedFS)(nil)
+)
+
+// cacheKey returns the key in the cache of the content of a file.
+func (c *cachedFS) cacheKey(name string, info fs.FileInfo) string {
+	return c.namespace + "\x00" + name + "\x00" + strconv.FormatInt(info.ModTime().Unix(), 10) + "\x00" + strconv.FormatInt(info.Size(), 10)
+}
+
+// Open implements interface [fs.FS].
+func (c *cachedFS) Open(name string) (fs.File, error) {
+	return c.OpenContext(context.Background(), name)
+}
+
+// OpenContext is like Open, but the wait for a read slot is bounded by ctx (see [WithMaxConcurrentReads]).
+func (c *cachedFS) OpenContext(ctx context.Context, name string) (fs.File, error) {
+	info, err := c.FS.Stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: unwrapPathError(err)}
+	}
+	if info.Mode().IsRegular() && c.canRead(info) {
+		key := c.cacheKey(name, info)
+		if data, ok := c.cache.Get(key); ok && int64(len(data)) == info.Size() {
+			return &cachedFile{Reader: bytes.NewReader(data), info: info}, nil
+		}
+		f, err := c.openContext(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return &fillingFile{File: f, cache: c.cache, key: key, size: info.Size()}, nil
+	}
+	return c.openContext(ctx, name)
+}
+
+// canRead returns true if the content of the regular file whose metadata is info can be read
+// from the wrapped FS, which must have been returned by [New].
+func (c *cachedFS) canRead(info fs.FileInfo) bool {
+	ar, ok := c.FS.(*arfs)
+	if !ok {
+		return false
+	}
+	fi, ok := info.(*fileinfo)
+	return ok && ar.canRead(fi.mode)
+}
+
+// openContext opens a file of ar with OpenContext if ar implements [ContextFS].
+func (c *cachedFS) openContext(ctx context.Context, name string) (fs.File, error) {
+	if ar, ok := c.FS.(ContextFS); ok {
+		return ar.OpenContext(ctx, name)
+	}
+	return c.FS.Open(name)
+}
+
+// ReadFile implements interface [fs.ReadFileFS], reading the file with Open to consult the cache.
+func (c *cachedFS) ReadFile(name string) ([]byte, error) {
+	return c.ReadFileContext(context.Background(), name)
+}
+
+// ReadFileContext is like ReadFile, but the wait for a read slot is bounded by ctx.
+func (c *cachedFS) ReadFileContext(ctx context.Context, name string) ([]byte, error) {
+	f, err := c.OpenContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// cachedFile is a regular file whose content comes from the cache of a [cachedFS].
+type cachedFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+// Stat implements interface [fs.File].
+func (f *cachedFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// Close implements interface [fs.File].
+func (f *cachedFile) Close() error {
+	return nil
+}
+
+// fillingFile is a regular file of a [cachedFS] that stores its content in the cache
+// once it has been read completely.
+type fillingFile struct {
+	fs.File
+	cache Cache
+	key   string
+	size  int64
+	buf   []byte // Content read so far. nil once stored, or if reading failed
+	err   bool   // Reading failed
+}
+
+// Read implements interface [fs.File].
+func (f *fillingFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if f.err {
+		return n, err
+	}
+	if f.buf == nil {
+		f.buf = make([]byte, 0, bufCap(f.size))
+	}
+	f.buf = append(f.buf, p[:n]...)
+	switch {
+	case err == io.EOF:
+		if int64(len(f.buf)) == f.size {
+			f.cache.Put(f.key, f.buf)
+		}
+		f.buf, f.err = nil, true
+	case err != nil:
+		f.buf, f.err = nil, true
+	}
+	return n, err
+}
@@ -0,0 +1,104 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// mapCache is an in-memory [sqlarfs.Cache].
+type mapCache struct {
+	mu         sync.Mutex
+	data       map[string][]byte
+	hits, puts int
+}
+
+func (c *mapCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.data[key]
+	if ok {
+		c.hits++
+	}
+	return data, ok
+}
+
+func (c *mapCache) Put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data == nil {
+		c.data = make(map[string][]byte)
+	}
+	c.data[key] = data
+	c.puts++
+}
+
+func TestWithCache(t *testing.T) {
+	db, counter := openCountingDB(t, "testdata/dir.sqlar")
+	cache := &mapCache{}
+	ar := sqlarfs.WithCache(sqlarfs.New(db, sqlarfs.WithReadOnlyGuarantee()), cache, "dir.sqlar")
+	ref := openFS(t, "testdata/dir.sqlar")
+
+	expected, err := fs.ReadFile(ref, "subdir/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.ReadFile(ar, "subdir/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(expected) || cache.puts != 1 || cache.hits != 0 {
+		t.Errorf("first read: got %q, %d puts, %d hits", b, cache.puts, cache.hits)
+	}
+
+	before := counter.queries.Load()
+	b, err = fs.ReadFile(ar, "subdir/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(expected) || cache.puts != 1 || cache.hits != 1 {
+		t.Errorf("second read: got %q, %d puts, %d hits", b, cache.puts, cache.hits)
+	}
+	if n := counter.queries.Load() - before; n != 0 {
+		t.Errorf("second read: %d queries, expected 0", n)
+	}
+
+	if err := fstest.TestFS(ar, "a.txt", "b.txt", "subdir/c.txt", "subdir/subdir2/e.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithCacheShared(t *testing.T) {
+	cache := &mapCache{}
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db, entry{name: "secret.txt", mode: syscall.S_IFREG | 0600, sz: 6, data: []byte("secret")})
+
+	// The permission mask applies to the content in the cache
+	owner := sqlarfs.WithCache(sqlarfs.New(db, sqlarfs.PermOwner), cache, "a")
+	if b, err := fs.ReadFile(owner, "secret.txt"); err != nil || string(b) != "secret" || cache.puts != 1 {
+		t.Fatalf("owner: got %q, %v, %d puts", b, err, cache.puts)
+	}
+	others := sqlarfs.WithCache(sqlarfs.New(db, sqlarfs.PermOthers), cache, "a")
+	if _, err := fs.ReadFile(others, "secret.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("others: got %v, expected ErrPermission", err)
+	}
+	if cache.hits != 0 {
+		t.Errorf("others: got %d hits, expected 0", cache.hits)
+	}
+
+	// Archives with the same entries but another content don't collide
+	db2 := createDB(t, sqlarSchema)
+	insertEntries(t, db2, entry{name: "secret.txt", mode: syscall.S_IFREG | 0600, sz: 6, data: []byte("public")})
+	other := sqlarfs.WithCache(sqlarfs.New(db2, sqlarfs.PermOwner), cache, "b")
+	if b, err := fs.ReadFile(other, "secret.txt"); err != nil || string(b) != "public" {
+		t.Errorf("other archive: got %q, %v", b, err)
+	}
+	if b, err := fs.ReadFile(owner, "secret.txt"); err != nil || string(b) != "secret" || cache.hits != 1 {
+		t.Errorf("owner: got %q, %v, %d hits", b, err, cache.hits)
+	}
+}
@@ -0,0 +1,82 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"io/fs"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// RepairDirs inserts in the sqlar table of db the missing rows for the directories
+// that are only implied by the paths of their content.
+//
+// The inserted directories have the permission bits of dirMode and the
+// modification time of their most recent descendant.
+//
+// RepairDirs runs in a transaction and is idempotent.
+func RepairDirs(db *sql.DB, dirMode fs.FileMode) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT name,mtime FROM sqlar`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	implied := make(map[string]int64) // Directory path => most recent mtime of descendants
+	for rows.Next() {
+		var name string
+		var mtime sql.NullInt64
+		if err := rows.Scan(&name, &mtime); err != nil {
+			return err
+		}
+		existing[name] = true
+		for dir := name; ; {
+			i := strings.LastIndexByte(dir, '/')
+			if i <= 0 {
+				break
+			}
+			dir = dir[:i]
+			if !fs.ValidPath(dir) {
+				continue
+			}
+			if t, seen := implied[dir]; !seen || mtime.Int64 > t {
+				implied[dir] = mtime.Int64
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	var missing []string
+	for dir := range implied {
+		if !existing[dir] {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+
+	stmt, err := tx.Prepare(`INSERT INTO sqlar(name,mode,mtime,sz,data) VALUES(?,?,?,0,NULL)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	mode := syscall.S_IFDIR | uint32(dirMode.Perm())
+	for _, dir := range missing {
+		if _, err := stmt.Exec(dir, mode, implied[dir]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
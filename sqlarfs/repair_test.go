@@ -0,0 +1,59 @@
+package sqlarfs_test
+
+import (
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestRepairDirs(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, mtime: 100, sz: 1, data: []byte("a")},
+		entry{name: "subdir/c.txt", mode: syscall.S_IFREG | 0644, mtime: 200, sz: 1, data: []byte("c")},
+		entry{name: "subdir/subdir2/e.txt", mode: syscall.S_IFREG | 0644, mtime: 300, sz: 1, data: []byte("e")},
+		entry{name: "subdir/subdir2/f.txt", mode: syscall.S_IFREG | 0644, mtime: 150, sz: 1, data: []byte("f")},
+	)
+
+	for i := 0; i < 2; i++ { // Check idempotence
+		if err := sqlarfs.RepairDirs(db, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := db.Query(`SELECT name,mode,mtime FROM sqlar WHERE mode&? ORDER BY name`, syscall.S_IFDIR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.name, &e.mode, &e.mtime); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(got)
+	expected := []entry{
+		{name: "subdir", mode: syscall.S_IFDIR | 0750, mtime: 300},
+		{name: "subdir/subdir2", mode: syscall.S_IFDIR | 0750, mtime: 300},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("got %v, expected %v", got, expected)
+	}
+	for i := range got {
+		if got[i].name != expected[i].name || got[i].mode != expected[i].mode || got[i].mtime != expected[i].mtime {
+			t.Errorf("got %v, expected %v", got[i], expected[i])
+		}
+	}
+
+	if err := fstest.TestFS(sqlarfs.New(db), "a.txt", "subdir/c.txt", "subdir/subdir2/e.txt", "subdir/subdir2/f.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestRootRow checks that the metadata of the root is read from a row named "." or "".
+func TestRootRow(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		entries []entry
+		mode    fs.FileMode
+		mtime   int64
+	}{
+		{"none", nil, fs.ModeDir | 0555, 0},
+		{"dot", []entry{{name: ".", mode: syscall.S_IFDIR | 0750, mtime: 1000}}, fs.ModeDir | 0750, 1000},
+		{"empty", []entry{{name: "", mode: syscall.S_IFDIR | 0700, mtime: 2000}}, fs.ModeDir | 0700, 2000},
+		{"both", []entry{
+			{name: "", mode: syscall.S_IFDIR | 0700, mtime: 2000},
+			{name: ".", mode: syscall.S_IFDIR | 0750, mtime: 1000},
+		}, fs.ModeDir | 0750, 1000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := createDB(t, sqlarSchema)
+			insertEntries(t, db, tc.entries...)
+			insertEntries(t, db, entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")})
+			ar := sqlarfs.New(db)
+
+			info, err := fs.Stat(ar, ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode() != tc.mode || info.ModTime().Unix() != tc.mtime {
+				t.Errorf("got %v %d, expected %v %d", info.Mode(), info.ModTime().Unix(), tc.mode, tc.mtime)
+			}
+			entries, err := fs.ReadDir(ar, ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != "a.txt" {
+				t.Errorf("ReadDir: got %v", names(entries))
+			}
+			if err := fstest.TestFS(ar, "a.txt"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
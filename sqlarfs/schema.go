@@ -0,0 +1,75 @@
+package sqlarfs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrSchema is returned (wrapped) when the sqlar table is missing or lacks some of the expected columns.
+var ErrSchema = errors.New("sqlarfs: unexpected schema")
+
+// requiredColumns are the columns of the sqlar table that are queried by this package.
+var requiredColumns = [...]string{"name", "mode", "mtime", "sz", "data"}
+
+type schemaCache struct {
+	mu      sync.Mutex
+	columns map[string]bool // Lower case column names. nil until successfully loaded.
+}
+
+// columns returns the set of columns (lower case) of the sqlar table.
+//
+// The result is cached once the schema has been validated.
+func (ar *arfs) columns() (map[string]bool, error) {
+	ar.schema.mu.Lock()
+	defer ar.schema.mu.Unlock()
+	if ar.schema.columns != nil {
+		return ar.schema.columns, nil
+	}
+
+	rows, err := ar.db.Query(`SELECT name FROM pragma_table_info('sqlar')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%w: table sqlar not found", ErrSchema)
+	}
+
+	var missing []string
+	for _, col := range requiredColumns {
+		if col == "name" && ar.nameColumn != "" {
+			if columns["name"] {
+				return nil, fmt.Errorf("%w: table sqlar has a column name in addition to column %s", ErrSchema, ar.nameColumn)
+			}
+			col = strings.ToLower(ar.nameColumn)
+		}
+		if !columns[col] {
+			missing = append(missing, col)
+		}
+	}
+	if missing != nil {
+		return nil, fmt.Errorf("%w: table sqlar lacks columns %s", ErrSchema, strings.Join(missing, ", "))
+	}
+
+	ar.schema.columns = columns
+	return columns, nil
+}
+
+// checkSchema checks that the sqlar table has the expected columns.
+func (ar *arfs) checkSchema() error {
+	_, err := ar.columns()
+	return err
+}
@@ -0,0 +1,60 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestSchemaMissingColumn(t *testing.T) {
+	db := createDB(t,
+		`CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, sz INT, data BLOB)`,
+		`INSERT INTO sqlar VALUES('a.txt', 33188, 1, x'61')`,
+	)
+	ar := sqlarfs.New(db)
+
+	for _, op := range []struct {
+		name string
+		f    func() error
+	}{
+		{"Stat", func() error { _, err := ar.Stat("a.txt"); return err }},
+		{"ReadDir", func() error { _, err := ar.ReadDir("."); return err }},
+		{"Open", func() error { _, err := ar.Open("."); return err }},
+	} {
+		err := op.f()
+		if !errors.Is(err, sqlarfs.ErrSchema) {
+			t.Errorf("%s: got %v, expected ErrSchema", op.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "mtime") {
+			t.Errorf("%s: error doesn't mention the missing column: %v", op.name, err)
+		}
+		t.Logf("%s: %v", op.name, err)
+	}
+}
+
+func TestSchemaMissingTable(t *testing.T) {
+	db := createDB(t)
+	ar := sqlarfs.New(db)
+	_, err := fs.Stat(ar, ".")
+	if !errors.Is(err, sqlarfs.ErrSchema) {
+		t.Fatalf("got %v, expected ErrSchema", err)
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	if _, err := sqlarfs.NewChecked(createDB(t)); !errors.Is(err, sqlarfs.ErrSchema) {
+		t.Errorf("no sqlar table: got %v, expected ErrSchema", err)
+	}
+
+	ar, err := sqlarfs.NewChecked(openDB(t, "testdata/simple.sqlar"), sqlarfs.PermOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ar.Stat("foo.txt"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,142 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"io/fs"
+)
+
+// Section returns a reader of n bytes of the content of regular file name, starting at offset off.
+//
+// If the file is stored uncompressed, the reader queries only the requested ranges of
+// the blob with SQL function substr. Otherwise the content is decompressed once in memory.
+// This is a convenient primitive for parsers of formats that read a header and a trailer.
+func (ar *arfs) Section(name string, off, n int64) (*io.SectionReader, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	var info *fileinfo
+	var err error
+	if name == "." {
+		info, err = ar.statRoot()
+	} else {
+		info, err = ar.stat(name)
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if !ar.canRead(info.mode) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrPermission}
+	}
+	if off < 0 || n < 0 || off > info.sz-n {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	r, err := ar.contentReaderAt(name, info)
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(r, off, n), nil
+}
+
+// contentReaderAt returns an [io.ReaderAt] of the content of the regular file name, whose metadata is info.
+//
+// If the file is stored uncompressed, the ranges of the blob are queried with SQL function substr.
+// Otherwise the content is decompressed once in memory.
+func (ar *arfs) contentReaderAt(name string, info *fileinfo) (sizedReaderAt, error) {
+	// Stored data can be read by ranges unless it must be decoded, verified or transformed as a whole
+	if ar.dataEncoding == Raw && !ar.verifyHash && ar.transform == nil && !ar.textNormalization {
+		var length, mtime, sz int64
+		err := ar.db.QueryRow(``+
+			`SELECT COALESCE(length(data),0),mtime,sz`+
+			` FROM `+ar.table()+
+			` WHERE name=?`+ar.collate()+
+			` AND `+ar.modeFilterReg(),
+			name,
+		).Scan(&length, &mtime, &sz)
+		switch err {
+		case nil:
+		case sql.ErrNoRows:
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+		default:
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		if !ar.readOnly && (mtime != info.mtime || sz != info.sz) {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: ErrChanged}
+		}
+		if length == info.sz {
+			return &blobReaderAt{ar: ar, name: name, size: length}, nil
+		}
+	}
+
+	r, err := ar.openContent(name, info)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	content, err := readAll(r, make([]byte, 0, ar.contentBufCap(info.sz)))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+// sizedReaderAt is an [io.ReaderAt] of the whole content of a file.
+type sizedReaderAt interface {
+	io.ReaderAt
+	// Size returns the length of the content, which is not sz if the content is
+	// transformed (see WithReadTransform).
+	Size() int64
+}
+
+// blobReaderAt reads ranges of the stored (uncompressed) data of a file.
+type blobReaderAt struct {
+	ar   *arfs
+	name string
+	size int64
+}
+
+// ReadAt implements interface [io.ReaderAt].
+func (b *blobReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: b.name, Err: fs.ErrInvalid}
+	}
+	if off >= b.size {
+		return 0, io.EOF
+	}
+	n := int64(len(p))
+	if n > b.size-off {
+		n = b.size - off
+	}
+	var data []byte
+	err := b.ar.db.QueryRow(``+
+		`SELECT substr(data,?,?)`+
+		` FROM `+b.ar.table()+
+		` WHERE name=?`+b.ar.collate()+
+		` AND `+b.ar.modeFilterReg(),
+		off+1, n, b.name, // substr is 1-based
+	).Scan(&data)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return 0, &fs.PathError{Op: "read", Path: b.name, Err: fs.ErrNotExist}
+	default:
+		return 0, &fs.PathError{Op: "read", Path: b.name, Err: err}
+	}
+	if int64(len(data)) != n {
+		return 0, &fs.PathError{Op: "read", Path: b.name, Err: ErrCorrupt}
+	}
+	copy(p, data)
+	if n < int64(len(p)) {
+		return int(n), io.EOF
+	}
+	return int(n), nil
+}
+
+// Size implements interface [sizedReaderAt].
+func (b *blobReaderAt) Size() int64 {
+	return b.size
+}
@@ -0,0 +1,59 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestSection(t *testing.T) {
+	content := []byte("HEADER" + string(bytes.Repeat([]byte("-"), 100)) + "middle" + string(bytes.Repeat([]byte("-"), 100)) + "TRAILER")
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "compressed.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: deflate(t, content)},
+		entry{name: "private.bin", mode: syscall.S_IFREG | 0200, sz: int64(len(content)), data: content},
+	)
+	ar := newFS(db)
+
+	off := int64(bytes.Index(content, []byte("middle")))
+	for _, name := range []string{"stored.bin", "compressed.bin"} {
+		sr, err := ar.Section(name, off, 6)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		b, err := io.ReadAll(sr)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if string(b) != "middle" {
+			t.Errorf("%s: got %q", name, b)
+		}
+
+		buf := make([]byte, 4)
+		if n, err := sr.ReadAt(buf, 3); n != 3 || err != io.EOF || string(buf[:n]) != "dle" {
+			t.Errorf("%s: ReadAt: got %d %q %v", name, n, buf[:n], err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		off, n int64
+		err    error
+	}{
+		{"stored.bin", -1, 2, fs.ErrInvalid},
+		{"stored.bin", 0, int64(len(content)) + 1, fs.ErrInvalid},
+		{"stored.bin", int64(len(content)), 1, fs.ErrInvalid},
+		{"missing.bin", 0, 1, fs.ErrNotExist},
+		{"private.bin", 0, 1, fs.ErrPermission},
+		{".", 0, 0, fs.ErrInvalid},
+	} {
+		if _, err := ar.Section(tc.name, tc.off, tc.n); !errors.Is(err, tc.err) {
+			t.Errorf("%s [%d:+%d]: got %v, expected %v", tc.name, tc.off, tc.n, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// ServeFile replies to the HTTP request r with the content of the regular file name from ar.
+//
+// The response has the Content-Length, Last-Modified and Content-Type headers set, and
+// Range and conditional requests (If-Modified-Since, If-None-Match...) are handled by
+// [net/http.ServeContent]. If the entry doesn't exist or is a directory, the reply is
+// a 404 error. If reading is not allowed (see [PermMask]), the reply is a 403 error.
+func ServeFile(w http.ResponseWriter, r *http.Request, ar fs.FS, name string) {
+	f, err := ar.Open(name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	if !info.Mode().IsRegular() {
+		serveError(w, fs.ErrNotExist)
+		return
+	}
+
+	// http.ServeContent requires an io.ReadSeeker.
+	// The content of a sqlar entry is loaded as a single blob anyway, so buffer it.
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		buf, err := readAll(f, make([]byte, 0, bufCap(info.Size())))
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		content = bytes.NewReader(buf)
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+// serveError replies with the HTTP status that matches err.
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,67 @@
+package sqlarfs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestServeFile(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	insertEntries(t, db,
+		entry{name: "dir", mode: syscall.S_IFDIR | 0755, mtime: mtime.Unix()},
+		entry{name: "dir/hello.txt", mode: syscall.S_IFREG | 0644, mtime: mtime.Unix(), sz: 11, data: []byte("Hello world")},
+	)
+	ar := sqlarfs.New(db)
+
+	serve := func(name string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/"+name, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		sqlarfs.ServeFile(w, req, ar, name)
+		return w
+	}
+
+	w := serve("dir/hello.txt", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("200: got status %d", w.Code)
+	}
+	for k, v := range map[string]string{
+		"Content-Length": "11",
+		"Content-Type":   "text/plain; charset=utf-8",
+		"Last-Modified":  mtime.Format(http.TimeFormat),
+	} {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("200: %s: got %q, expected %q", k, got, v)
+		}
+	}
+	if body := w.Body.String(); body != "Hello world" {
+		t.Errorf("200: got body %q", body)
+	}
+
+	w = serve("dir/hello.txt", http.Header{"If-Modified-Since": {mtime.Format(http.TimeFormat)}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("304: got status %d", w.Code)
+	}
+
+	w = serve("dir/hello.txt", http.Header{"Range": {"bytes=6-"}})
+	if w.Code != http.StatusPartialContent {
+		t.Errorf("206: got status %d", w.Code)
+	} else if body := w.Body.String(); body != "world" {
+		t.Errorf("206: got body %q", body)
+	}
+
+	for _, name := range []string{"missing.txt", "dir"} {
+		if w := serve(name, nil); w.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d, expected 404", name, w.Code)
+		}
+	}
+}
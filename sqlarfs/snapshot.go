@@ -0,0 +1,35 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"io/fs"
+)
+
+// WalkSnapshot is like [fs.WalkDir] but all the queries of the walk are run
+// in a single read transaction, so the walk sees a consistent snapshot
+// of the archive even if the database is modified concurrently (this requires
+// the database to be in [WAL mode]).
+//
+// The transaction is released when the walk completes. Files opened by fn
+// with the FS are read outside of the snapshot.
+//
+// [WAL mode]: https://sqlite.org/wal.html
+func (ar *arfs) WalkSnapshot(root string, fn fs.WalkDirFunc) error {
+	db, ok := ar.db.(*sql.DB)
+	if !ok {
+		// Already running in a transaction
+		return fs.WalkDir(ar, root, fn)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return fs.WalkDir(ar.snapshot(tx), root, fn)
+}
+
+// snapshot returns a copy of ar (with the same options but empty caches) that runs its queries with tx.
+// The limits of concurrent reads and of bandwidth are shared with ar.
+func (ar *arfs) snapshot(tx *sql.Tx) *arfs {
+	return &arfs{db: tx, options: ar.options, caches: new(caches), reads: ar.reads, rate: ar.rate}
+}
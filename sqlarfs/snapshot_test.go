@@ -0,0 +1,53 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestWalkSnapshot(t *testing.T) {
+	db := createDB(t, `PRAGMA journal_mode=WAL`, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "a.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("a")},
+		entry{name: "subdir/b.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("b")},
+	)
+
+	walk := func(t *testing.T, walk func(root string, fn fs.WalkDirFunc) error, newFile string) []string {
+		var paths []string
+		err := walk(".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+			// Pause the walk to modify the archive
+			if path == "a.txt" {
+				insertEntries(t, db, entry{name: newFile, mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("c")})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(paths)
+		return paths
+	}
+
+	// Control: without snapshot, the new file is visible in the walk
+	ar := newFS(db)
+	if paths := walk(t, func(root string, fn fs.WalkDirFunc) error {
+		return fs.WalkDir(ar, root, fn)
+	}, "subdir/c.txt"); len(paths) != 5 {
+		t.Fatalf("WalkDir: got %q", paths)
+	}
+
+	ar = newFS(db)
+	if paths := walk(t, ar.WalkSnapshot, "subdir/d.txt"); len(paths) != 5 {
+		t.Fatalf("WalkSnapshot: got %q", paths)
+	}
+
+	// The new file is visible after the walk
+	if _, err := ar.Stat("subdir/d.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,120 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"io"
+	"io/fs"
+)
+
+// sparseChunkSize is the size of the ranges of data read by the readers of WithSparseReads.
+const sparseChunkSize = 64 * 1024
+
+// WithSparseReads is an [Option] for [New] that reads the content of files stored uncompressed
+// by ranges of 64 KiB, instead of loading the whole data in memory, and that doesn't transfer
+// the ranges that are filled with zeros: they are checked by SQLite and synthesized.
+// This bounds the memory used to read large, mostly zero-filled files, such as disk images.
+//
+// Files stored compressed, and files whose content must be decoded, verified or
+// transformed as a whole ([WithDataEncoding], [WithVerifyHash], [WithReadTransform],
+// [WithTextNormalization]), are read as usual.
+func WithSparseReads() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.sparseReads = true
+	})
+}
+
+// openSparse returns a reader of the content of the regular file name, whose metadata is info,
+// that reads its data by ranges. It returns nil if the file is not stored uncompressed.
+//
+// As SQLite loads the whole blob to extract a range, a file filled only with zeros
+// is detected upfront to synthesize its content without any further query.
+func (ar *arfs) openSparse(name string, info *fileinfo) (io.ReadCloser, error) {
+	if ar.dataEncoding != Raw || ar.verifyHash || ar.transform != nil || ar.textNormalization {
+		return nil, nil
+	}
+	var length, mtime, sz int64
+	var zero bool
+	err := ar.db.QueryRow(``+
+		`SELECT COALESCE(length(data),0),mtime,sz,COALESCE(data=zeroblob(length(data)),1)`+
+		` FROM `+ar.table()+
+		` WHERE name=?`+ar.collate()+
+		` AND `+ar.modeFilterReg(),
+		name,
+	).Scan(&length, &mtime, &sz, &zero)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	default:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	if !ar.readOnly && (mtime != info.mtime || sz != info.sz) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: ErrChanged}
+	}
+	if length != info.sz {
+		return nil, nil // Compressed
+	}
+	if zero {
+		return io.NopCloser(io.LimitReader(zeroReader{}, length)), nil
+	}
+	return &sparseReader{ar: ar, name: name, size: length}, nil
+}
+
+// zeroReader is an endless source of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// sparseReader reads the stored data of a file by ranges, synthesizing the ranges filled with zeros.
+type sparseReader struct {
+	ar   *arfs
+	name string
+	size int64
+	off  int64
+}
+
+func (r *sparseReader) Read(p []byte) (int, error) {
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	n := int64(len(p))
+	if n > sparseChunkSize {
+		n = sparseChunkSize
+	}
+	if n > r.size-r.off {
+		n = r.size - r.off
+	}
+	var data []byte // NULL if the range is filled with zeros
+	err := r.ar.db.QueryRow(``+
+		`SELECT CASE WHEN s=zeroblob(length(s)) THEN NULL ELSE s END`+
+		` FROM (SELECT substr(data,?,?) AS s`+
+		` FROM `+r.ar.table()+
+		` WHERE name=?`+r.ar.collate()+
+		` AND `+r.ar.modeFilterReg()+`)`,
+		r.off+1, n, r.name, // substr is 1-based
+	).Scan(&data)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return 0, &fs.PathError{Op: "read", Path: r.name, Err: fs.ErrNotExist}
+	default:
+		return 0, &fs.PathError{Op: "read", Path: r.name, Err: err}
+	}
+	if data == nil {
+		clear(p[:n])
+	} else {
+		if int64(len(data)) != n {
+			return 0, &fs.PathError{Op: "read", Path: r.name, Err: ErrCorrupt}
+		}
+		copy(p, data)
+	}
+	r.off += n
+	return int(n), nil
+}
+
+func (r *sparseReader) Close() error {
+	return nil
+}
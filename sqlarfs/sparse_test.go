@@ -0,0 +1,77 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestSparseReads(t *testing.T) {
+	const size = 64 << 20
+	db := createDB(t, sqlarSchema)
+	if _, err := db.Exec(``+
+		`INSERT INTO sqlar (name,mode,mtime,sz,data) VALUES`+
+		` ('zero.img',?,0,?,zeroblob(?)),`+
+		` ('mixed.img',?,0,200003,zeroblob(100000)||x'414243'||zeroblob(100000))`,
+		syscall.S_IFREG|0644, size, size, syscall.S_IFREG|0644,
+	); err != nil {
+		t.Fatal(err)
+	}
+	mixed := append(append(make([]byte, 100000), "ABC"...), make([]byte, 100000)...)
+	insertEntries(t, db,
+		entry{name: "deflated.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(mixed)), data: deflate(t, mixed)},
+	)
+	ar := sqlarfs.New(db, sqlarfs.WithSparseReads())
+
+	for _, name := range []string{"mixed.img", "deflated.txt"} {
+		f, err := ar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !bytes.Equal(b, mixed) {
+			t.Errorf("%s: unexpected content", name)
+		}
+	}
+
+	f, err := ar.Open("zero.img")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var nonZero int
+	buf := make([]byte, 32*1024)
+	n, err := io.CopyBuffer(writerFunc(func(p []byte) (int, error) {
+		for _, c := range p {
+			if c != 0 {
+				nonZero++
+			}
+		}
+		return len(p), nil
+	}), f, buf)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != size || nonZero != 0 {
+		t.Errorf("got %d bytes (%d non zero), expected %d zeros", n, nonZero, size)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > size/8 {
+		t.Errorf("%d bytes allocated to read %d bytes", alloc, size)
+	}
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (w writerFunc) Write(p []byte) (int, error) {
+	return w(p)
+}
@@ -5,26 +5,155 @@ package sqlarfs
 
 import (
 	"bytes"
-	"compress/flate"
+	"context"
 	"database/sql"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"path/filepath"
 	"sort"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode/utf8"
 )
 
 // FS documents the [io/fs] interfaces provided by this implementation of [io/fs.FS].
+//
+// The FS returned by [New] also implements [io/fs.ReadFileFS], [io/fs.GlobFS] and the
+// optional interfaces [ContextFS], [ContentFS], [StorageFS], [ListFS], [TreeFS], [CacheFS]
+// and [ConfigFS], whose methods are available with a type assertion:
+//
+//	if tree, ok := ar.(sqlarfs.TreeFS); ok {
+//		size, err := tree.DirSize("subdir")
+//	}
 type FS interface {
 	fs.FS
 	fs.StatFS
 	fs.ReadDirFS
 }
 
+// ContextFS is implemented by an [FS] whose reads can be bound to a [context.Context].
+type ContextFS interface {
+	FS
+	// OpenContext opens a file, waiting for a read slot until ctx is done.
+	OpenContext(ctx context.Context, name string) (fs.File, error)
+	// ReadFileContext reads the content of a file, stopping when ctx is done.
+	ReadFileContext(ctx context.Context, name string) ([]byte, error)
+}
+
+// ContentFS is implemented by an [FS] that provides other ways than Open to read the content of files.
+type ContentFS interface {
+	FS
+	// ReadFiles reads the content of multiple files.
+	ReadFiles(names []string) (map[string][]byte, error)
+	// ReadFileHash reads the content of a file and writes it to a hash.
+	ReadFileHash(name string, h hash.Hash) ([]byte, error)
+	// ReadFileToBuffer appends the content of a file to a buffer owned by the caller.
+	ReadFileToBuffer(name string, buf *bytes.Buffer) error
+	// OpenConcat returns a reader of the contents of several files, in order.
+	OpenConcat(names ...string) (io.ReadCloser, error)
+	// OpenPooled returns a reader of the content of a file that releases pooled resources on Close.
+	OpenPooled(name string) (io.ReadCloser, error)
+	// Section returns a reader of a range of the content of a file.
+	Section(name string, off, n int64) (*io.SectionReader, error)
+	// OpenAs opens a file, enforcing permissions with another mask.
+	OpenAs(name string, mask PermMask) (fs.File, error)
+	// OpenFirstMatch opens the first entry matching a pattern.
+	OpenFirstMatch(pattern string) (fs.File, string, error)
+	// OpenSqlar opens a file that is itself an SQLite Archive File.
+	OpenSqlar(name string, opts ...Option) (FS, io.Closer, error)
+}
+
+// StorageFS is implemented by an [FS] that reports how the entries are stored in the sqlar table.
+type StorageFS interface {
+	FS
+	// OpenWithInfo opens a file and returns how it is stored.
+	OpenWithInfo(name string) (fs.File, FileHeader, error)
+	// OpenCRC returns the stored data of a file with the CRC-32 of its content.
+	OpenCRC(name string) (raw io.ReadCloser, method Method, uncompressedSize int64, crc32 uint32, err error)
+	// CopyRawTo writes the stored data of a file to w, without decompression.
+	CopyRawTo(name string, w io.Writer) (n int64, method Method, err error)
+	// EstimateCompression reports the stored size of a file and an estimate of its best compressed size.
+	EstimateCompression(name string) (currentStored, bestEstimate int64, err error)
+	// CompressionFormat reports the framing of the compressed data of the archive.
+	CompressionFormat() (Format, error)
+	// ModeConvention reports how the producer of the archive stored the type of entries.
+	ModeConvention() (Convention, error)
+	// BrokenEntries reports the entries that can't be read.
+	BrokenEntries() ([]BrokenEntry, error)
+}
+
+// ListFS is implemented by an [FS] that provides other ways than ReadDir to list a directory.
+type ListFS interface {
+	FS
+	// DirEntries returns an iterator over the entries of a directory.
+	DirEntries(name string) func(yield func(fs.DirEntry, error) bool)
+	// ReadDirVisible lists a directory, with dotfiles separated.
+	ReadDirVisible(dir string) (visible, hidden []fs.DirEntry, err error)
+	// ReadDirSorted reads a directory with entries sorted by name, size or modification time.
+	ReadDirSorted(dir string, by SortKey, desc bool) ([]fs.DirEntry, error)
+	// Page returns a page of the entries of a directory.
+	Page(dir, after string, limit int) ([]fs.DirEntry, string, error)
+	// Exists reports which of names exist.
+	Exists(names []string) (map[string]bool, error)
+}
+
+// TreeFS is implemented by an [FS] that answers queries about whole subtrees of the archive.
+type TreeFS interface {
+	FS
+	// Tree returns the listing of all the directories of a subtree.
+	Tree(root string) (map[string][]fs.DirEntry, error)
+	// WalkSnapshot walks the tree in a consistent snapshot of the archive.
+	WalkSnapshot(root string, fn fs.WalkDirFunc) error
+	// AllDirs returns the paths of all the directories.
+	AllDirs() ([]string, error)
+	// DirSize returns the total size of the regular files below a directory.
+	DirSize(name string) (int64, error)
+	// DirSummaries returns the count of children and the total size of all the directories of a subtree.
+	DirSummaries(root string) (map[string]DirSummary, error)
+	// CommonPrefix returns the deepest directory containing all entries.
+	CommonPrefix() (string, error)
+	// ListBySize returns the regular files whose size is in a range, largest first.
+	ListBySize(min, max int64) ([]fs.FileInfo, error)
+	// Extensions returns the number of regular files per extension.
+	Extensions() (map[string]int64, error)
+	// Inaccessible returns the paths of the entries that are not accessible under the permission mask.
+	Inaccessible() ([]string, error)
+}
+
+// CacheFS is implemented by an [FS] with caches of metadata.
+type CacheFS interface {
+	FS
+	// Warm fills the caches of metadata with a single scan of the archive.
+	Warm() error
+	// CacheStats returns the counters of the caches of metadata.
+	CacheStats() CacheStats
+}
+
+// ConfigFS is implemented by an [FS] that exposes how it was created.
+type ConfigFS interface {
+	FS
+	// DB returns the database handle given to New.
+	DB() *sql.DB
+	// Config returns the effective configuration set with the options of New.
+	Config() Config
+}
+
+var (
+	_ fs.ReadFileFS = (*arfs)(nil)
+	_ fs.GlobFS     = (*arfs)(nil)
+	_ ContextFS     = (*arfs)(nil)
+	_ ContentFS     = (*arfs)(nil)
+	_ StorageFS     = (*arfs)(nil)
+	_ ListFS        = (*arfs)(nil)
+	_ TreeFS        = (*arfs)(nil)
+	_ CacheFS       = (*arfs)(nil)
+	_ ConfigFS      = (*arfs)(nil)
+)
+
 // New returns an instance of [io/fs.FS] that allows to access the files in an [SQLite Archive File] opened with [database/sql].
 //
 // db is a [database/sql] handle to the SQLite Archive file. Two drivers are known to work: [github.com/mattn/sqlite3] and [modernc.org/sqlite].
@@ -39,37 +168,144 @@ type FS interface {
 //
 // [SQLite Archive File]: https://sqlite.org/sqlar.html
 func New(db *sql.DB, opts ...Option) FS {
-	ar := &arfs{db: db, permMask: PermAny}
+	ar := &arfs{db: db, options: options{permMask: PermAny}, caches: new(caches)}
 	for _, o := range opts {
 		o.apply(ar)
 	}
+	if ar.maxConcurrentReads > 0 {
+		ar.reads = make(chan struct{}, ar.maxConcurrentReads)
+	}
+	if ar.readRateLimit > 0 {
+		ar.rate = newRateLimiter(ar.readRateLimit)
+	}
 	return ar
 }
 
+// NewChecked is like [New] but eagerly checks the schema of the sqlar table and
+// queries the root directory, so that a broken archive is reported immediately
+// instead of on first use.
+func NewChecked(db *sql.DB, opts ...Option) (FS, error) {
+	ar := New(db, opts...).(*arfs)
+	if _, err := ar.statRoot(); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
 type arfs struct {
-	db       *sql.DB
+	db querier
+	options
+	*caches
+
+	permOverride bool // permMask applies even with WithPermForUser. See OpenAs
+
+	reads chan struct{} // Semaphore of concurrent reads. See WithMaxConcurrentReads
+	rate  *rateLimiter  // See WithReadRateLimit
+
+	flatePool flatePool // Pool of DEFLATE decompressors
+}
+
+// caches are the caches of metadata of an [FS]. They may be shared with views of the FS
+// that enforce other permissions (see OpenAs), as their content doesn't depend on the permission mask.
+type caches struct {
+	schema   schemaCache
+	dirInfo  dirInfoCache
+	fileInfo dirInfoCache  // Cache for regular files. See WithReadOnlyGuarantee
+	notExist negativeCache // See WithNegativeCache
+}
+
+// querier is the subset of the methods of [*database/sql.DB] used for querying.
+// It is also implemented by [*database/sql.Tx].
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// options are the settings of an [FS] that are set with [Option]s.
+type options struct {
 	permMask PermMask
 
-	dirInfo dirInfoCache
+	maxDecompressRatio float64 // 0: no limit
+	maxDecompressBytes int64   // 0: no limit
+
+	negativeCacheTTL time.Duration // 0: no negative cache
+
+	readOnly      bool // See WithReadOnlyGuarantee
+	assumeRegular bool // See WithAssumeRegularWhenNoType
+
+	modeConvention Convention // See WithModeConvention
+
+	maxConcurrentReads int // 0: no limit
+
+	verifyHash bool // See WithVerifyHash
+
+	trimTrailingSlash bool // See WithTrailingSlash
+
+	dataEncoding DataEncoding // See WithDataEncoding
+
+	collation string // See WithCollation
+
+	permUser *permUser // See WithPermForUser
+
+	keep func(path string, mode uint32) bool // See WithEntryFilter
+
+	location *time.Location // See WithLocation
+
+	transform func(path string, r io.Reader) (io.Reader, error) // See WithReadTransform
+	flateDict []byte                                            // See WithFlateDictionary
+
+	lazyReadDir bool // See WithLazyReadDir
+
+	likeEscape *likeEscape // See WithLikeEscape
+
+	sqliteExtCompat bool // See WithSQLiteExtCompat
+
+	nameColumn string // See WithNameColumn
+
+	textNormalization bool // See WithTextNormalization
+
+	readRateLimit int64 // Bytes per second. 0: no limit. See WithReadRateLimit
+
+	sparseReads bool // See WithSparseReads
+
+	progress func(name string, read, total int64) // See WithReadProgress
+
+	lenientRead bool // See WithLenientRead
 }
 
 func (ar *arfs) canRead(mode uint32) bool {
-	return mode&0444&uint32(ar.permMask) != 0
+	return mode&0444&ar.permMaskFor(mode) != 0
 }
 
 func (ar *arfs) canTraverse(mode uint32) bool {
-	return mode&0111&uint32(ar.permMask) != 0
+	return mode&0111&ar.permMaskFor(mode) != 0
 }
 
 var _ FS = (*arfs)(nil)
 
 // Option is an option for [New].
 //
-// Available options: [PermOwner], [PermGroup], [PermOthers], [PermAny].
+// Available options: [PermOwner], [PermGroup], [PermOthers], [PermAny],
+// [WithMaxDecompressRatio], [WithMaxDecompressBytes], [WithNegativeCache],
+// [WithReadOnlyGuarantee], [WithAssumeRegularWhenNoType], [WithMaxConcurrentReads],
+// [WithVerifyHash], [WithTrailingSlash], [WithDataEncoding], [WithCollation],
+// [WithPermForUser], [WithEntryFilter], [WithLocation], [WithReadTransform],
+// [WithFlateDictionary], [WithLazyReadDir], [WithLikeEscape], [WithSQLiteExtCompat],
+// [WithNameColumn], [WithModeConvention], [WithTextNormalization], [WithReadRateLimit],
+// [WithSparseReads], [WithReadProgress], [WithLenientRead].
 type Option interface {
 	apply(*arfs)
 }
 
+// optionFunc is an [Option] implemented as a function.
+type optionFunc func(*arfs)
+
+func (o optionFunc) apply(ar *arfs) {
+	o(ar)
+}
+
 const (
 	PermOwner  PermMask = 0700
 	PermGroup  PermMask = 0070
@@ -100,6 +336,9 @@ type fileinfo struct {
 	mode  uint32
 	mtime int64
 	sz    int64
+	loc   *time.Location // Location of ModTime. See WithLocation
+
+	stored int64 // Size of the 'data' column. Only set by walkTree
 }
 
 var _ interface {
@@ -142,12 +381,16 @@ func (fi *fileinfo) Mode() fs.FileMode {
 
 // IsDir implements interface [fs.FileInfo].
 func (fi *fileinfo) IsDir() bool {
-	return fi.mode&syscall.S_IFDIR != 0
+	return isDirMode(fi.mode)
 }
 
 // ModTime implements interface [fs.FileInfo].
 func (fi *fileinfo) ModTime() time.Time {
-	return time.Unix(fi.mtime, 0)
+	t := time.Unix(fi.mtime, 0)
+	if fi.loc != nil {
+		t = t.In(fi.loc)
+	}
+	return t
 }
 
 // Sys implements interface [fs.FileInfo].
@@ -157,13 +400,21 @@ func (fi *fileinfo) Sys() any {
 
 type dirInfoCache struct {
 	mu   sync.RWMutex
-	info map[string]*fileinfo // Keys are directory paths validated with io/fs.ValidPath
+	info map[string]*fileinfo // Keys are paths validated with io/fs.ValidPath
+
+	hits, misses atomic.Uint64 // See CacheStats
 }
 
 func (di *dirInfoCache) load(path string) *fileinfo {
 	di.mu.RLock()
-	defer di.mu.RUnlock()
-	return di.info[path]
+	fi := di.info[path]
+	di.mu.RUnlock()
+	if fi != nil {
+		di.hits.Add(1)
+	} else {
+		di.misses.Add(1)
+	}
+	return fi
 }
 
 func (di *dirInfoCache) store(path string, fi *fileinfo) *fileinfo {
@@ -181,19 +432,35 @@ func (di *dirInfoCache) store(path string, fi *fileinfo) *fileinfo {
 	return fi
 }
 
-const escapeLikeChar = "§"
-
-var escapeLike = strings.NewReplacer("%", escapeLikeChar+"%", "_", escapeLikeChar+"_", "!", escapeLikeChar+"!")
-
+// The type of an entry is the value of the S_IFMT bits of its mode, not a combination of
+// S_IF* bits: S_IFLNK (0120000) includes the bit of S_IFREG (0100000), and S_IFSOCK (0140000)
+// includes the bits of both S_IFREG and S_IFDIR (0040000).
+// Only directories and regular files are supported: other types (symbolic links, devices...)
+// are skipped, like broken modes.
 const (
 	dirMode          uint32 = syscall.S_IFDIR | 0555
-	sqlModeFilter           = `((mode&49152)>>9)<>0` // Skip files with broken mode: 49152 = syscall.S_IFREG|syscall.S_IFDIR
-	sqlModeFilterDir        = `(mode&16384)<>0`      // 16384 = syscall.S_IFDIR => directories
-	sqlModeFilterReg        = `(mode&32768)<>0`      // 32768 = syscall.S_IFREG => regular files
+	sqlModeFilter           = `(mode&61440) IN (16384,32768)` // Skip files with broken mode: 61440 = syscall.S_IFMT, 16384 = syscall.S_IFDIR, 32768 = syscall.S_IFREG
+	sqlModeFilterDir        = `(mode&61440)=16384`            // 16384 = syscall.S_IFDIR => directories
+	sqlModeFilterReg        = `(mode&61440)=32768`            // 32768 = syscall.S_IFREG => regular files
 )
 
+// isDirMode reports whether mode is the mode of a directory.
+func isDirMode(mode uint32) bool {
+	return mode&syscall.S_IFMT == syscall.S_IFDIR
+}
+
+// isRegMode reports whether mode is the mode of a regular file.
+func isRegMode(mode uint32) bool {
+	return mode&syscall.S_IFMT == syscall.S_IFREG
+}
+
+// ReadDir implements interface [fs.ReadDirFS].
+//
+// If the archive has a file with the same path as a directory implied by the
+// paths of other entries ("a" and "a/b"), the file wins: the directory and its
+// content are hidden, as they are not reachable with Stat or Open.
 func (ar *arfs) ReadDir(name string) ([]fs.DirEntry, error) {
-	list, err := ar.readDir(name)
+	list, err := ar.readDir(ar.cleanPath(name))
 	if len(list) > 0 {
 		sort.Slice(list, func(i, j int) bool {
 			return list[i].Name() < list[j].Name()
@@ -203,10 +470,19 @@ func (ar *arfs) ReadDir(name string) ([]fs.DirEntry, error) {
 }
 
 func (ar *arfs) readDir(name string) ([]fs.DirEntry, error) {
+	return ar.readDirOrder(name, "")
+}
+
+// readDirOrder is like readDir, but the entries are sorted with the SQL ORDER BY clause orderBy
+// (on the columns n, t, s: name, mtime and size), or unsorted if orderBy is "".
+func (ar *arfs) readDirOrder(name string, orderBy string) ([]fs.DirEntry, error) {
 	if !fs.ValidPath(name) {
 		return nil, fs.ErrInvalid
 	}
 	if name == "." {
+		if _, err := ar.statRoot(); err != nil {
+			return nil, err
+		}
 		name = ""
 	} else {
 		fi, err := ar.stat(name)
@@ -222,26 +498,27 @@ func (ar *arfs) readDir(name string) ([]fs.DirEntry, error) {
 		name = name + "/"
 	}
 
-	nameEsc := escapeLike.Replace(name)
+	nameEsc := ar.escapeLike(name)
 	rows, err := ar.db.Query(``+
 		// Files
-		`SELECT SUBSTR(name,?),mode,mtime,sz`+
-		` FROM sqlar`+
-		` WHERE name LIKE ? ESCAPE '`+escapeLikeChar+`'`+
-		` AND name NOT LIKE ? ESCAPE '`+escapeLikeChar+`'`+
-		` AND `+sqlModeFilter+ // Skip files with broken mode
+		`SELECT SUBSTR(name,?) AS n,`+ar.modeExpr()+`,mtime AS t,sz AS s,0`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND name NOT LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+ar.modeFilter()+ // Skip files with broken mode
+		` AND `+sqlValidName+
 		` UNION ALL`+
-		// Subdirectories: emulate entries from filenames in subdirs
-		` SELECT DISTINCT SUBSTR(name, ?, INSTR(SUBSTR(name, ?), '/')-1),16749,0,0`+ // mode is: syscall.S_IFDIR | 0555
-		` FROM sqlar`+
-		` WHERE name LIKE ? ESCAPE '`+escapeLikeChar+`'`+
-		` AND name NOT LIKE ? ESCAPE '`+escapeLikeChar+`'`,
-		1+len(name),
+		// Subdirectories: emulate entries from filenames in subdirs (at any depth)
+		` SELECT DISTINCT SUBSTR(name, ?, INSTR(SUBSTR(name, ?), '/')-1),16749,0,0,1`+ // mode is: syscall.S_IFDIR | 0555
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+sqlValidName+
+		orderBy,
+		1+utf8.RuneCountInString(name), // SUBSTR counts characters
 		nameEsc+"_%",
 		nameEsc+"%/%",
-		1+len(name), 1+len(name),
+		1+utf8.RuneCountInString(name), 1+utf8.RuneCountInString(name),
 		nameEsc+"_%/%",
-		nameEsc+"%/%/%",
 	)
 	if err != nil {
 		return nil, err
@@ -249,37 +526,68 @@ func (ar *arfs) readDir(name string) ([]fs.DirEntry, error) {
 
 	defer rows.Close()
 
-	var entries []fs.DirEntry
-	var subdirs map[string]struct{}
+	var infos []*fileinfo
+	var seen map[string]int // Index in infos
+
+	var implied []bool // implied[i]: infos[i] is emulated from the paths of other entries
 
 	for rows.Next() {
-		fi := new(fileinfo)
-		if err := fi.scan(rows.Scan); err != nil {
-			return entries, err
+		fi := ar.newFileinfo()
+		var emulated bool
+		if err := rows.Scan(&fi.name, &fi.mode, &fi.mtime, &fi.sz, &emulated); err != nil {
+			return nil, err
+		}
+		// sqlValidName can't check the UTF-8 encoding
+		if !fs.ValidPath(name + fi.name) {
+			continue
 		}
 		// Some archives may have entries for directories
-		// In that case we ignore the duplicates we created in the SQL
-		if fi.IsDir() {
-			if _, seen := subdirs[fi.name]; seen {
+		// In that case we ignore the duplicates we created in the SQL.
+		// If a file has the same name as a directory implied by the paths of
+		// other files, the file wins (as in stat).
+		// The entry that wins takes its own place in the order.
+		if i, dup := seen[fi.name]; dup {
+			if fi.IsDir() && (emulated || !implied[i]) {
 				continue
 			}
-			if subdirs == nil {
-				subdirs = make(map[string]struct{})
-			}
-			subdirs[fi.name] = struct{}{}
-			fi = ar.dirInfo.store(name+"/"+fi.name, fi)
+			infos[i] = nil
 		}
-		entries = append(entries, fs.FileInfoToDirEntry(fi))
+		if seen == nil {
+			seen = make(map[string]int)
+		}
+		seen[fi.name] = len(infos)
+		infos = append(infos, fi)
+		implied = append(implied, emulated)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != err {
-		return entries, err
+	// name is "" or has a trailing '/'
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, fi := range infos {
+		if fi == nil { // Duplicate
+			continue
+		}
+		if ar.hidden(name+fi.name, fi.mode) {
+			continue
+		}
+		if fi.IsDir() {
+			fi = ar.dirInfo.store(name+fi.name, fi)
+		} else if ar.readOnly {
+			// Allow Open of the listed files without a query
+			fi = ar.fileInfo.store(name+fi.name, fi)
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
 	}
 	return entries, rows.Close()
 }
 
 // Stat implements interface [fs.StatFS].
 func (ar *arfs) Stat(name string) (fs.FileInfo, error) {
+	dirOnly := ar.dirOnly(name)
+	name = ar.cleanPath(name)
 	if name == "." {
 		info, err := ar.statRoot()
 		if err != nil {
@@ -293,6 +601,9 @@ func (ar *arfs) Stat(name string) (fs.FileInfo, error) {
 	}
 
 	fi, err := ar.stat(name)
+	if err == nil && dirOnly && !fi.IsDir() {
+		err = fs.ErrNotExist
+	}
 	if err != nil {
 		// Avoid returning (*fileinfo)(nil) instead of (fs.FileInfo)(nil)
 		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
@@ -307,69 +618,70 @@ var fileinfoRoot = fileinfo{
 	sz:    0,
 }
 
+// statRoot returns the metadata of the root directory.
+//
+// The metadata of the root is read from the row named "." or, as stored by some
+// producers, from the row with an empty name ("." wins if both exist).
+// Without such a row, the root is a directory with mode 0555.
+// Those rows are never enumerated as entries of the root.
 func (ar *arfs) statRoot() (*fileinfo, error) {
 	var fi *fileinfo
 	fi = ar.dirInfo.load(".")
 	if fi != nil {
 		return fi, nil
 	}
-	fi = new(fileinfo)
+	if err := ar.checkSchema(); err != nil {
+		return nil, err
+	}
+	fi = ar.newFileinfo()
 	err := fi.scan(ar.db.QueryRow(`` +
-		`SELECT '.',mode,mtime,sz` +
-		` FROM sqlar` +
-		` WHERE name='.'` +
+		`SELECT '.',` + ar.modeExpr() + `,mtime,sz` +
+		` FROM ` + ar.table() +
+		` WHERE name IN ('.','')` +
+		` ORDER BY name DESC` + // "." first
 		` LIMIT 1`).Scan)
 	switch err {
 	case sql.ErrNoRows:
-		fi = &fileinfoRoot
+		root := fileinfoRoot
+		root.loc = ar.location
+		fi = &root
 		fallthrough
 	case nil:
 		return ar.dirInfo.store(".", fi), nil
 	default:
-		// Table sqlar doesn't exist or other error
+		// Database error
 		return nil, err
 	}
 }
 
 // Stat implements interface [fs.StatFS].
 func (ar *arfs) stat(name string) (*fileinfo, error) {
-	dir, filename := filepath.Split(name)
-	if dir == "" {
-		fi, err := ar.statRoot()
-		if err != nil {
-			return nil, &fs.PathError{Op: "stat", Path: ".", Err: err}
-		}
-		if !ar.canTraverse(fi.mode) {
-			return nil, fs.ErrPermission
-		}
-	} else {
-		// Recursively check that we can traverse the tree
-		// Note: dir has a trailing '/'
-		fi, err := ar.stat(dir[:len(dir)-1])
-		if err != nil {
-			return nil, &fs.PathError{Op: "stat", Path: dir[:len(dir)-1], Err: err}
-		}
-		if !fi.IsDir() {
-			return nil, fs.ErrNotExist
-		}
-		if !ar.canTraverse(fi.mode) {
-			return nil, fs.ErrPermission
-		}
+	filename, err := ar.traverseParent(name)
+	if err != nil {
+		return nil, err
 	}
 
 	info := ar.dirInfo.load(name)
 	if info != nil {
 		return info, nil
 	}
+	if ar.readOnly {
+		if info = ar.fileInfo.load(name); info != nil {
+			return info, nil
+		}
+	}
+	if ar.negativeCacheTTL > 0 && ar.notExist.has(name) {
+		return nil, fs.ErrNotExist
+	}
 
-	info = new(fileinfo)
+	info = ar.newFileinfo()
 
-	err := info.scan(
+	err = info.scan(
 		ar.db.QueryRow(``+
-			`SELECT name,mode,mtime,sz`+
-			` FROM sqlar`+
-			` WHERE name=?`+
-			` AND `+sqlModeFilter+ // Skip file with broken mode
+			`SELECT name,`+ar.modeExpr()+`,mtime,sz`+
+			` FROM `+ar.table()+
+			` WHERE name=?`+ar.collate()+
+			` AND `+ar.modeFilter()+ // Skip file with broken mode
 			` LIMIT 1`,
 			name,
 		).Scan)
@@ -381,16 +693,20 @@ func (ar *arfs) stat(name string) (*fileinfo, error) {
 		var ok bool
 		err = ar.db.QueryRow(``+
 			`SELECT 1`+
-			` FROM sqlar`+
-			` WHERE SUBSTR(name,?)=?`+
+			` FROM `+ar.table()+
+			` WHERE SUBSTR(name,1,?)=?`+ar.collate()+
+			` AND `+sqlValidName+
 			` LIMIT 1`,
-			len(name)+1,
+			utf8.RuneCountInString(name)+1, // SUBSTR counts characters
 			name+"/",
 		).Scan(&ok)
 		switch {
 		case err == nil && ok:
 			info.mode = dirMode
 		case err == sql.ErrNoRows || err == nil: // Case "err == nil" should never happen
+			if ar.negativeCacheTTL > 0 {
+				ar.notExist.add(name, ar.negativeCacheTTL)
+			}
 			return nil, fs.ErrNotExist
 		default:
 			return nil, err
@@ -400,21 +716,62 @@ func (ar *arfs) stat(name string) (*fileinfo, error) {
 	}
 	info.name = filename
 
+	if ar.hidden(name, info.mode) {
+		return nil, fs.ErrNotExist
+	}
+
 	if info.IsDir() {
 		info = ar.dirInfo.store(name, info)
+	} else if ar.readOnly {
+		info = ar.fileInfo.store(name, info)
 	}
 
 	return info, nil
 }
 
+// traverseParent checks that the parent directories of name can be traversed.
+// It returns the base name of name.
+func (ar *arfs) traverseParent(name string) (string, error) {
+	dir, filename := filepath.Split(name)
+	if dir == "" {
+		// The root is cached: no query after the first lookup
+		fi, err := ar.statRoot()
+		if err != nil {
+			return "", &fs.PathError{Op: "stat", Path: ".", Err: err}
+		}
+		if !ar.canTraverse(fi.mode) {
+			return "", fs.ErrPermission
+		}
+	} else {
+		// Recursively check that we can traverse the tree
+		// Note: dir has a trailing '/'
+		fi, err := ar.stat(dir[:len(dir)-1])
+		if err != nil {
+			return "", &fs.PathError{Op: "stat", Path: dir[:len(dir)-1], Err: err}
+		}
+		if !fi.IsDir() {
+			return "", fs.ErrNotExist
+		}
+		if !ar.canTraverse(fi.mode) {
+			return "", fs.ErrPermission
+		}
+	}
+	return filename, nil
+}
+
 // file gives access to a file in an SQLite Archive file.
 //
-// *file implements interface [fs.File].
+// *file implements interfaces [fs.File] and [io.ReaderAt].
 type file struct {
 	fs   *arfs
 	info fileinfo
 	path string
 	r    io.ReadCloser
+	slot bool            // Holds a slot of the semaphore of concurrent reads. See WithMaxConcurrentReads
+	ctx  context.Context // Context of OpenContext. See WithReadRateLimit
+
+	mu sync.Mutex    // Protects fs and ra for concurrent calls of ReadAt
+	ra sizedReaderAt // See ReadAt
 }
 
 // dir gives access to a directory in an SQLite Archive file.
@@ -423,6 +780,7 @@ type file struct {
 type dir struct {
 	file
 	entries []fs.DirEntry
+	lazy    *lazyDir // See WithLazyReadDir
 }
 
 // Stat implements interface [fs.File].
@@ -439,35 +797,27 @@ func (f *file) Read(b []byte) (int, error) {
 		if !f.fs.canRead(f.info.mode) {
 			return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrPermission}
 		}
-		var buf []byte
-		err := f.fs.db.QueryRow(``+
-			`SELECT data`+
-			` FROM sqlar`+
-			` WHERE name=?`+
-			` AND `+sqlModeFilterReg,
-			f.path,
-		).Scan(&buf)
-		switch err {
-		case nil:
-			// OK
-		case sql.ErrNoRows:
-			return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrNotExist}
-		default:
-			return 0, &fs.PathError{Op: "read", Path: f.path, Err: err}
-		}
-		if len(buf) == int(f.info.sz) {
-			f.r = io.NopCloser(bytes.NewReader(buf))
-		} else {
-			f.r = flate.NewReader(bytes.NewReader(buf))
+		var err error
+		f.r, err = f.fs.openContent(f.path, &f.info)
+		if err != nil {
+			return 0, err
 		}
+		f.r = f.fs.rateLimitReader(f.ctx, f.path, f.r)
+		f.r = f.fs.progressReader(f.path, &f.info, f.r)
 	}
 	return f.r.Read(b)
 }
 
 // Close implements interface [fs.File].
 func (f *file) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	r := f.r
-	f.fs, f.r = nil, nil
+	if f.slot {
+		f.slot = false
+		f.fs.releaseRead()
+	}
+	f.fs, f.r, f.ra = nil, nil, nil
 	if r == nil {
 		return nil
 	}
@@ -479,6 +829,9 @@ func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
 	if d.file.fs == nil {
 		return nil, fs.ErrClosed
 	}
+	if d.file.fs.lazyReadDir {
+		return d.readDirLazy(n)
+	}
 
 	// FIXME naive implementation
 
@@ -506,7 +859,20 @@ func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
 }
 
 // Open implements interface [fs.FS].
+//
+// As usual with [io/fs], the handle of a directory implements [fs.ReadDirFile], while the
+// handle of a regular file doesn't: use a type assertion to list a directory.
+// The two types are kept distinct so that generic code can rely on the assertion
+// to tell directories from files, as with [testing/fstest.MapFS].
 func (ar *arfs) Open(name string) (fs.File, error) {
+	return ar.OpenContext(context.Background(), name)
+}
+
+// OpenContext is like Open but, if the number of concurrent reads is limited
+// (see [WithMaxConcurrentReads]), ctx bounds the wait for a slot.
+func (ar *arfs) OpenContext(ctx context.Context, name string) (fs.File, error) {
+	dirOnly := ar.dirOnly(name)
+	name = ar.cleanPath(name)
 	var info *fileinfo
 	var err error
 	if name == "." {
@@ -519,6 +885,9 @@ func (ar *arfs) Open(name string) (fs.File, error) {
 			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
 		}
 		info, err = ar.stat(name)
+		if err == nil && dirOnly && !info.IsDir() {
+			err = fs.ErrNotExist
+		}
 		if err != nil {
 			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
 		}
@@ -528,5 +897,9 @@ func (ar *arfs) Open(name string) (fs.File, error) {
 		return &dir{file: file{fs: ar, info: *info, path: name}}, nil
 	}
 
-	return &file{fs: ar, info: *info, path: name}, nil
+	f := &file{fs: ar, info: *info, path: name, ctx: ctx}
+	if err := ar.acquireRead(ctx, f); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f, nil
 }
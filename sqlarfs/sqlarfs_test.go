@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -39,10 +40,74 @@ func openDB(tb testing.TB, path string) *sql.DB {
 	return db
 }
 
-func openFS(tb testing.TB, path string, opts ...sqlarfs.Option) fs.FS {
+// extFS is the set of the interfaces implemented by the FS returned by [sqlarfs.New].
+type extFS interface {
+	sqlarfs.ContextFS
+	sqlarfs.ContentFS
+	sqlarfs.StorageFS
+	sqlarfs.ListFS
+	sqlarfs.TreeFS
+	sqlarfs.CacheFS
+	sqlarfs.ConfigFS
+	fs.ReadFileFS
+	fs.GlobFS
+}
+
+// newFS is [sqlarfs.New] giving access to the methods of the optional interfaces.
+func newFS(db *sql.DB, opts ...sqlarfs.Option) extFS {
+	return sqlarfs.New(db, opts...).(extFS)
+}
+
+func openFS(tb testing.TB, path string, opts ...sqlarfs.Option) extFS {
 	tb.Helper()
 	db := openDB(tb, path)
-	return sqlarfs.New(db, opts...)
+	return newFS(db, opts...)
+}
+
+const sqlarSchema = `CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB)`
+
+// createDB creates a temporary SQLite database (read-write) and initializes it with the given SQL statements.
+func createDB(tb testing.TB, stmts ...string) *sql.DB {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "test.sqlar")
+	db, err := sql.Open(sqliteDriver, "file:"+path)
+	if err != nil {
+		tb.Fatalf("open %q: %v", path, err)
+	}
+
+	tb.Cleanup(func() {
+		err := db.Close()
+		if err != nil {
+			tb.Error("close archive DB:", err)
+		}
+	})
+
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			tb.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return db
+}
+
+// entry is a row of the sqlar table.
+type entry struct {
+	name  string
+	mode  uint32
+	mtime int64
+	sz    int64
+	data  []byte
+}
+
+// insertEntries inserts rows in the sqlar table.
+func insertEntries(tb testing.TB, db *sql.DB, entries ...entry) {
+	tb.Helper()
+	for _, e := range entries {
+		_, err := db.Exec(`INSERT INTO sqlar(name,mode,mtime,sz,data) VALUES(?,?,?,?,?)`, e.name, e.mode, e.mtime, e.sz, e.data)
+		if err != nil {
+			tb.Fatalf("insert %q: %v", e.name, err)
+		}
+	}
 }
 
 func TestEmpty(t *testing.T) {
@@ -0,0 +1,70 @@
+package sqlarfs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// textSniffLen is the length of the start of the content checked for NUL bytes to detect
+// text files. See WithTextNormalization.
+const textSniffLen = 512
+
+// WithTextNormalization is an [Option] for [New] that converts line endings from CRLF to LF
+// when text files are read, for text-processing tools working with archives from any platform.
+// A file is handled as text if its first 512 bytes have no NUL byte: other files are read as is.
+//
+// The conversion is streaming and applies after decompression and after the transform set
+// with [WithReadTransform]. The size reported by Stat is the size before the conversion,
+// so it doesn't match the length read from a converted file.
+func WithTextNormalization() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.textNormalization = true
+	})
+}
+
+// newTextReader returns a reader of r that converts CRLF to LF, unless the start of the
+// content of r has a NUL byte.
+func newTextReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	// Errors are reported by the following reads
+	if head, _ := br.Peek(textSniffLen); bytes.IndexByte(head, 0) >= 0 {
+		return br // Binary
+	}
+	return &crlfReader{r: br}
+}
+
+// crlfReader converts CRLF to LF.
+type crlfReader struct {
+	r   *bufio.Reader
+	err error // Error to report after the data already converted
+}
+
+func (cr *crlfReader) Read(p []byte) (int, error) {
+	if cr.err != nil {
+		return 0, cr.err
+	}
+	n := 0
+	for n < len(p) {
+		b, err := cr.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				cr.err = err
+				return n, nil
+			}
+			return 0, err
+		}
+		if b == '\r' {
+			if next, err := cr.r.Peek(1); err == nil && next[0] == '\n' {
+				continue
+			}
+		}
+		p[n] = b
+		n++
+		if cr.r.Buffered() == 0 {
+			// Don't block for more data
+			break
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,61 @@
+package sqlarfs_test
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestTextNormalization(t *testing.T) {
+	large := strings.Repeat("line\r\n", 5000)
+	binary := "\x00\x01\r\n\x02"
+	crlf := "a\r\nb\rc\r\n\r\nd\n\re\r\r\n"
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "crlf.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(crlf)), data: []byte(crlf)},
+		entry{name: "large.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(large)), data: deflate(t, []byte(large))},
+		entry{name: "binary.bin", mode: syscall.S_IFREG | 0644, sz: int64(len(binary)), data: []byte(binary)},
+		entry{name: "empty.txt", mode: syscall.S_IFREG | 0644},
+	)
+	ar := newFS(db, sqlarfs.WithTextNormalization())
+
+	for name, expected := range map[string]string{
+		"crlf.txt":   "a\nb\rc\n\nd\n\re\r\n",
+		"large.txt":  strings.Repeat("line\n", 5000),
+		"binary.bin": binary,
+		"empty.txt":  "",
+	} {
+		b, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(b) != expected {
+			t.Errorf("%s: got %q, expected %q", name, b, expected)
+		}
+
+		// Line endings split across reads
+		f, err := ar.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err = io.ReadAll(iotest.OneByteReader(f))
+		f.Close()
+		if err != nil || string(b) != expected {
+			t.Errorf("%s: byte by byte: got %q, %v", name, b, err)
+		}
+
+		section, err := ar.Section(name, 0, 0)
+		if err != nil || section.Size() != 0 {
+			t.Errorf("%s: Section: %v", name, err)
+		}
+	}
+
+	// Stat reports the stored size
+	if info, err := fs.Stat(ar, "crlf.txt"); err != nil || info.Size() != int64(len(crlf)) {
+		t.Errorf("Stat: got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,30 @@
+package sqlarfs
+
+// WithTrailingSlash is an [Option] for [New] that makes the FS tolerant of a single
+// trailing '/' in the paths of directories given by users: "subdir/" is accepted
+// as "subdir" by Open, Stat, ReadDir and the other methods of [FS] that take a
+// directory path. As with [os.Stat], the path of a file with a trailing '/'
+// ("a.txt/") is reported as not existing.
+//
+// By default, such paths are rejected with [io/fs.ErrInvalid], as required by [io/fs.ValidPath].
+// Note that with this option the FS doesn't pass [testing/fstest.TestFS].
+func WithTrailingSlash() Option {
+	return optionFunc(func(ar *arfs) {
+		ar.trimTrailingSlash = true
+	})
+}
+
+// cleanPath strips a trailing '/' from name if enabled with [WithTrailingSlash].
+// The callers that may reach a file must check it with dirOnly.
+func (ar *arfs) cleanPath(name string) string {
+	if ar.trimTrailingSlash && len(name) > 1 && name[len(name)-1] == '/' {
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// dirOnly returns true if name, as given by the user, has a trailing '/' stripped by
+// cleanPath: it can only be a directory. "a.txt/" doesn't exist if "a.txt" is a file.
+func (ar *arfs) dirOnly(name string) bool {
+	return ar.cleanPath(name) != name
+}
@@ -0,0 +1,71 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestTrailingSlash(t *testing.T) {
+	strict := openFS(t, "testdata/dir.sqlar")
+	tolerant := openFS(t, "testdata/dir.sqlar", sqlarfs.WithTrailingSlash())
+
+	for _, name := range []string{"subdir/", "subdir/subdir2/"} {
+		if _, err := strict.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("strict Open(%q): got %v, expected ErrInvalid", name, err)
+		}
+		if _, err := strict.ReadDir(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("strict ReadDir(%q): got %v, expected ErrInvalid", name, err)
+		}
+
+		f, err := tolerant.Open(name)
+		if err != nil {
+			t.Errorf("Open(%q): %v", name, err)
+		} else {
+			if fi, _ := f.Stat(); !fi.IsDir() {
+				t.Errorf("Open(%q): not a directory", name)
+			}
+			f.Close()
+		}
+		if _, err := tolerant.Stat(name); err != nil {
+			t.Errorf("Stat(%q): %v", name, err)
+		}
+		got, err := tolerant.ReadDir(name)
+		if err != nil {
+			t.Errorf("ReadDir(%q): %v", name, err)
+			continue
+		}
+		expected, _ := strict.ReadDir(name[:len(name)-1])
+		if len(got) != len(expected) {
+			t.Errorf("ReadDir(%q): got %d entries, expected %d", name, len(got), len(expected))
+		}
+	}
+
+	// A file is not a directory
+	if _, err := tolerant.Open("a.txt/"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(%q): got %v, expected ErrNotExist", "a.txt/", err)
+	}
+	if _, err := tolerant.Stat("a.txt/"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(%q): got %v, expected ErrNotExist", "a.txt/", err)
+	}
+	if _, err := tolerant.ReadFile("a.txt/"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile(%q): got %v, expected ErrNotExist", "a.txt/", err)
+	}
+	if _, _, err := tolerant.OpenWithInfo("a.txt/"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenWithInfo(%q): got %v, expected ErrNotExist", "a.txt/", err)
+	}
+	if _, err := tolerant.OpenConcat("a.txt/"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenConcat(%q): got %v, expected ErrNotExist", "a.txt/", err)
+	}
+	if exists, err := tolerant.Exists([]string{"a.txt", "a.txt/", "subdir/"}); err != nil || !exists["a.txt"] || exists["a.txt/"] || !exists["subdir/"] {
+		t.Errorf("Exists: got %v, %v", exists, err)
+	}
+
+	for _, name := range []string{"/", "subdir//", "a.txt//"} {
+		if _, err := tolerant.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%q): got %v, expected ErrInvalid", name, err)
+		}
+	}
+}
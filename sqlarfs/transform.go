@@ -0,0 +1,52 @@
+package sqlarfs
+
+import (
+	"io"
+	"io/fs"
+)
+
+// WithReadTransform is an [Option] for [New] that sets a transform applied to the content
+// of files when they are read (Open, ReadFiles, Section), to layer decryption or charset
+// conversion for example. The transform must be stream-friendly: it gets the reader of the
+// content of the file path and returns the reader of the transformed content.
+//
+// The transform is applied after decompression, so after the limits of decompression
+// ([WithMaxDecompressRatio], [WithMaxDecompressBytes]) and the verification of the hash of
+// the stored content ([WithVerifyHash]). The size reported by Stat is the size before the transform.
+// OpenCRC and OpenWithInfo describe the stored content, without the transform.
+func WithReadTransform(transform func(path string, r io.Reader) (io.Reader, error)) Option {
+	return optionFunc(func(ar *arfs) {
+		ar.transform = transform
+	})
+}
+
+// transformReader applies the transform set with [WithReadTransform] and the conversion of line endings
+// (see [WithTextNormalization]) to r, the reader of the content of name.
+func (ar *arfs) transformReader(name string, r io.ReadCloser) (io.ReadCloser, error) {
+	if ar.transform == nil && !ar.textNormalization {
+		return r, nil
+	}
+	var tr io.Reader = r
+	if ar.transform != nil {
+		var err error
+		tr, err = ar.transform(name, r)
+		if err != nil {
+			r.Close()
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+	}
+	if ar.textNormalization {
+		tr = newTextReader(tr)
+	}
+	return &transformedReader{Reader: tr, c: r}, nil
+}
+
+// transformedReader closes the reader of the content when the transformed content is closed.
+type transformedReader struct {
+	io.Reader
+	c io.Closer
+}
+
+func (t *transformedReader) Close() error {
+	return t.c.Close()
+}
@@ -0,0 +1,74 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// rot13Reader applies ROT13 to the ASCII letters of a stream.
+type rot13Reader struct {
+	r io.Reader
+}
+
+func (r rot13Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i, c := range p[:n] {
+		switch {
+		case c >= 'a' && c <= 'z':
+			p[i] = 'a' + (c-'a'+13)%26
+		case c >= 'A' && c <= 'Z':
+			p[i] = 'A' + (c-'A'+13)%26
+		}
+	}
+	return n, err
+}
+
+func TestReadTransform(t *testing.T) {
+	content := bytes.Repeat([]byte("Hello, World! "), 50)
+	expected := strings.Repeat("Uryyb, Jbeyq! ", 50)
+	db := createDB(t, sqlarSchema)
+	insertEntries(t, db,
+		entry{name: "stored.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content},
+		entry{name: "compressed.txt", mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: deflate(t, content)},
+		entry{name: "fail.txt", mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")},
+	)
+	errTransform := errors.New("transform failed")
+	ar := newFS(db, sqlarfs.WithReadTransform(func(path string, r io.Reader) (io.Reader, error) {
+		if path == "fail.txt" {
+			return nil, errTransform
+		}
+		return rot13Reader{r}, nil
+	}))
+
+	for _, name := range []string{"stored.txt", "compressed.txt"} {
+		b, err := fs.ReadFile(ar, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(b) != expected {
+			t.Errorf("%s: got %q", name, b)
+		}
+	}
+	files, err := ar.ReadFiles([]string{"compressed.txt"})
+	if err != nil {
+		t.Error(err)
+	} else if string(files["compressed.txt"]) != expected {
+		t.Errorf("ReadFiles: got %q", files["compressed.txt"])
+	}
+	sr, err := ar.Section("stored.txt", 0, 5)
+	if err != nil {
+		t.Error(err)
+	} else if b, _ := io.ReadAll(sr); string(b) != "Uryyb" {
+		t.Errorf("Section: got %q", b)
+	}
+
+	if _, err := fs.ReadFile(ar, "fail.txt"); !errors.Is(err, errTransform) {
+		t.Errorf("fail.txt: got %v, expected %v", err, errTransform)
+	}
+}
@@ -0,0 +1,177 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Tree returns the listing of all the directories below directory root (root included),
+// indexed by path, using a single query.
+//
+// Each list is sorted by name, like with ReadDir. Permissions are enforced like with [fs.WalkDir]:
+// the content of directories that can't be read or that are below a directory that
+// can't be traversed is omitted. Directories with no visible content have no key,
+// except root.
+func (ar *arfs) Tree(root string) (map[string][]fs.DirEntry, error) {
+	root = ar.cleanPath(root)
+	if !fs.ValidPath(root) {
+		return nil, &fs.PathError{Op: "readdir", Path: root, Err: fs.ErrInvalid}
+	}
+	tree := map[string][]fs.DirEntry{root: {}}
+	err := ar.walkTree(root, func(p string, fi *fileinfo) error {
+		dir := path.Dir(p)
+		tree[dir] = append(tree[dir], fs.FileInfoToDirEntry(fi))
+		return nil
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: root, Err: err}
+	}
+	for _, list := range tree {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Name() < list[j].Name()
+		})
+	}
+	return tree, nil
+}
+
+// walkTree calls fn for each entry below directory root (root excluded), using a single query.
+//
+// Entries are visited depth first: a directory is visited before its content,
+// but the entries of a directory are not necessarily in lexical order.
+// Directories that are only implied by the paths of their content are synthesized.
+// Permissions are enforced like with [fs.WalkDir]: the content of a directory that
+// can't be read is skipped, and so is the content of the subdirectories of a
+// directory that can't be traversed.
+//
+// The path passed to fn is relative to the root of the archive. The name of fi is the base name.
+// fi must not be modified by fn.
+func (ar *arfs) walkTree(root string, fn func(path string, fi *fileinfo) error) error {
+	type dirState struct {
+		path string // with a trailing '/', except for the archive root
+		list bool   // the content of the directory can be listed
+		trav bool   // the directory and all its parents can be traversed
+	}
+	var top dirState
+
+	if root == "." {
+		fi, err := ar.statRoot()
+		if err != nil {
+			return err
+		}
+		top = dirState{list: true, trav: ar.canTraverse(fi.mode)}
+	} else {
+		fi, err := ar.stat(root)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return fs.ErrInvalid
+		}
+		if !ar.canRead(fi.mode) {
+			return fs.ErrPermission
+		}
+		top = dirState{path: root + "/", list: true, trav: ar.canTraverse(fi.mode)}
+	}
+
+	// Sorting on name||'/' ensures that a directory row ("a" => "a/") is
+	// immediately followed by its content ("a/b" => "a/b/") even if some
+	// siblings ("a.txt" => "a.txt/") are lower than the content in lexical order.
+	rows, err := ar.db.Query(``+
+		`SELECT name,`+ar.modeExpr()+`,mtime,sz,COALESCE(`+ar.dataLengthExpr()+`,0)`+
+		` FROM `+ar.table()+
+		` WHERE name LIKE ? ESCAPE '`+ar.escapeChar()+`'`+
+		` AND `+sqlValidName+
+		` ORDER BY name||'/'`,
+		ar.escapeLike(top.path)+"_%",
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stack := []dirState{top}
+	var lastFile string // Path (with a trailing '/') of the last file
+	for rows.Next() {
+		var name string
+		fi := ar.newFileinfo()
+		if err := rows.Scan(&name, &fi.mode, &fi.mtime, &fi.sz, &fi.stored); err != nil {
+			return err
+		}
+		if !fs.ValidPath(name) { // sqlValidName can't check the UTF-8 encoding
+			continue
+		}
+		// A file hides the directory with the same name implied by the paths
+		// of the following entries (see ReadDir)
+		if lastFile != "" && strings.HasPrefix(name, lastFile) {
+			continue
+		}
+
+		// Leave the directories that are not ancestors of name
+		for len(stack) > 1 && !strings.HasPrefix(name, stack[len(stack)-1].path) {
+			stack = stack[:len(stack)-1]
+		}
+		top := stack[len(stack)-1]
+		// Enter the directories implied by name
+		for {
+			rest := name[len(top.path):]
+			i := strings.IndexByte(rest, '/')
+			if i < 0 {
+				break
+			}
+			dirPath := top.path + rest[:i]
+			if ar.hidden(dirPath, dirMode) {
+				// Hide the directory and its content
+				top = dirState{path: dirPath + "/"}
+				stack = append(stack, top)
+				continue
+			}
+			d := ar.dirInfo.store(dirPath, &fileinfo{name: rest[:i], mode: dirMode, loc: ar.location})
+			if top.list {
+				if err := fn(dirPath, d); err != nil {
+					return err
+				}
+			}
+			top = dirState{
+				path: dirPath + "/",
+				list: top.trav && ar.canRead(d.mode),
+				trav: top.trav && ar.canTraverse(d.mode),
+			}
+			stack = append(stack, top)
+		}
+
+		if !isDirMode(fi.mode) && !isRegMode(fi.mode) { // Skip files with broken mode (see sqlModeFilter)
+			continue
+		}
+		fi.name = name[len(top.path):]
+		if ar.hidden(name, fi.mode) {
+			if fi.IsDir() {
+				// Hide the content
+				stack = append(stack, dirState{path: name + "/"})
+			} else {
+				lastFile = name + "/"
+			}
+			continue
+		}
+		if !fi.IsDir() {
+			lastFile = name + "/"
+		} else {
+			fi = ar.dirInfo.store(name, fi)
+			stack = append(stack, dirState{
+				path: name + "/",
+				list: top.trav && ar.canRead(fi.mode),
+				trav: top.trav && ar.canTraverse(fi.mode),
+			})
+		}
+		if top.list {
+			if err := fn(name, fi); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,62 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestTree(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		opts []sqlarfs.Option
+	}{
+		{"testdata/dir.sqlar", nil},
+		{"testdata/perms.sqlar", []sqlarfs.Option{sqlarfs.PermOwner}},
+		{"testdata/perms.sqlar", []sqlarfs.Option{sqlarfs.PermOthers}},
+	} {
+		ar := openFS(t, tc.file, tc.opts...)
+		for _, root := range []string{".", "subdir"} {
+			tree, err := ar.Tree(root)
+			if root != "." && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s %s: %v", tc.file, root, err)
+				continue
+			}
+
+			// Compare with ReadDir on each directory
+			seen := 0
+			fs.WalkDir(ar, root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || !d.IsDir() {
+					return nil
+				}
+				expected, err := ar.ReadDir(path)
+				if err != nil || len(expected) == 0 && path != root {
+					if _, found := tree[path]; found {
+						t.Errorf("%s %s: unexpected listing of %s", tc.file, root, path)
+					}
+					return nil
+				}
+				seen++
+				got := tree[path]
+				if len(got) != len(expected) {
+					t.Errorf("%s %s: %s: got %d entries, expected %d", tc.file, root, path, len(got), len(expected))
+					return nil
+				}
+				for i := range got {
+					if got[i].Name() != expected[i].Name() || got[i].Type() != expected[i].Type() {
+						t.Errorf("%s %s: %s: got %s, expected %s", tc.file, root, path, got[i], expected[i])
+					}
+				}
+				return nil
+			})
+			if seen != len(tree) {
+				t.Errorf("%s %s: got %d directories, expected %d", tc.file, root, len(tree), seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package sqlarfs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// WriteTreeJSON writes to w a JSON representation of the tree of directory root of ar.
+//
+// Each node is an object with properties "name", "type" ("dir" or "file"),
+// "size", "mtime" and, for directories, "children" (array of nodes):
+//
+//	{"name":".","type":"dir","size":0,"mtime":"1970-01-01T00:00:00Z","children":[...]}
+//
+// The JSON is streamed: the tree is never entirely loaded in memory.
+// If ar is an [FS] created by [New], the tree is built from a single query.
+// Directories whose content is not accessible under the permission mask are
+// written without their children.
+func WriteTreeJSON(w io.Writer, ar fs.FS, root string) error {
+	info, err := fs.Stat(ar, root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &fs.PathError{Op: "readdir", Path: root, Err: fs.ErrInvalid}
+	}
+
+	tw := treeJSONWriter{w: bufio.NewWriter(w)}
+	if err := tw.add(root, info); err != nil {
+		return err
+	}
+
+	if a, ok := ar.(*arfs); ok {
+		err = a.walkTree(root, func(path string, fi *fileinfo) error {
+			return tw.add(path, fi)
+		})
+		if err != nil {
+			return &fs.PathError{Op: "readdir", Path: root, Err: err}
+		}
+	} else {
+		err = fs.WalkDir(ar, root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrPermission) {
+					return fs.SkipDir
+				}
+				return err
+			}
+			if path == root {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return tw.add(path, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tw.close()
+}
+
+// treeJSONWriter writes nodes, received in depth-first order, as nested JSON objects.
+type treeJSONWriter struct {
+	w     *bufio.Writer
+	dirs  []string // Stack of the open directories (paths with a trailing '/')
+	first bool     // No child has been written yet in the current directory
+}
+
+type treeJSONNode struct {
+	Name  string    `json:"name"`
+	Type  string    `json:"type"`
+	Size  int64     `json:"size"`
+	MTime time.Time `json:"mtime"`
+}
+
+func (tw *treeJSONWriter) add(p string, info fs.FileInfo) error {
+	// Close the directories that are not ancestors
+	for len(tw.dirs) > 1 && !strings.HasPrefix(p, tw.dirs[len(tw.dirs)-1]) {
+		tw.closeDir()
+	}
+	if len(tw.dirs) > 0 && !tw.first {
+		tw.w.WriteByte(',')
+	}
+	tw.first = false
+
+	node := treeJSONNode{Name: info.Name(), Type: "file", Size: info.Size(), MTime: info.ModTime().UTC()}
+	if len(tw.dirs) == 0 {
+		node.Name = path.Base(p)
+	}
+	if info.IsDir() {
+		node.Type = "dir"
+	}
+	b, err := json.Marshal(&node)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		// Replace the closing '}' to append the children
+		tw.w.Write(b[:len(b)-1])
+		tw.w.WriteString(`,"children":[`)
+		if p == "." {
+			p = ""
+		} else {
+			p += "/"
+		}
+		tw.dirs = append(tw.dirs, p)
+		tw.first = true
+	} else {
+		tw.w.Write(b)
+	}
+	return nil
+}
+
+func (tw *treeJSONWriter) closeDir() {
+	tw.w.WriteString("]}")
+	tw.dirs = tw.dirs[:len(tw.dirs)-1]
+	tw.first = false
+}
+
+func (tw *treeJSONWriter) close() error {
+	for len(tw.dirs) > 0 {
+		tw.closeDir()
+	}
+	return tw.w.Flush()
+}
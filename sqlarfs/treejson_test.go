@@ -0,0 +1,102 @@
+package sqlarfs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+type jsonNode struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Size     int64       `json:"size"`
+	Children []*jsonNode `json:"children"`
+}
+
+func (n *jsonNode) paths(prefix string, list []string) []string {
+	for _, c := range n.Children {
+		p := prefix + c.Name
+		list = append(list, p)
+		list = c.paths(p+"/", list)
+	}
+	return list
+}
+
+func TestWriteTreeJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fsys fs.FS
+	}{
+		{"sqlarfs", openFS(t, "testdata/dir.sqlar", sqlarfs.PermOwner)},
+		{"DirFS", os.DirFS("testdata/dir")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, root := range []string{".", "subdir"} {
+				var buf bytes.Buffer
+				if err := sqlarfs.WriteTreeJSON(&buf, tc.fsys, root); err != nil {
+					t.Fatal(err)
+				}
+				t.Logf("%s: %s", root, buf.Bytes())
+				if !json.Valid(buf.Bytes()) {
+					t.Fatalf("%s: invalid JSON", root)
+				}
+				var tree jsonNode
+				if err := json.Unmarshal(buf.Bytes(), &tree); err != nil {
+					t.Fatal(err)
+				}
+
+				var expected []string
+				fs.WalkDir(tc.fsys, root, func(path string, d fs.DirEntry, err error) error {
+					if path != root {
+						expected = append(expected, path)
+					}
+					return err
+				})
+				prefix := root + "/"
+				if root == "." {
+					prefix = ""
+				}
+				got := tree.paths(prefix, nil)
+				if len(got) != len(expected) {
+					t.Fatalf("%s: got %q, expected %q", root, got, expected)
+				}
+				seen := make(map[string]bool)
+				for _, p := range got {
+					seen[p] = true
+				}
+				for _, p := range expected {
+					if !seen[p] {
+						t.Errorf("%s: missing %q", root, p)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTreeJSONPerms(t *testing.T) {
+	ar := openFS(t, "testdata/perms.sqlar", sqlarfs.PermOwner)
+	var buf bytes.Buffer
+	if err := sqlarfs.WriteTreeJSON(&buf, ar, "."); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%s", buf.Bytes())
+	var tree jsonNode
+	if err := json.Unmarshal(buf.Bytes(), &tree); err != nil {
+		t.Fatal(err)
+	}
+	got := tree.paths("", nil)
+	expected := map[string]bool{"group": true, "others": true, "user": true, "user/u.txt": true}
+	if len(got) != len(expected) {
+		t.Fatalf("got %q", got)
+	}
+	for _, p := range got {
+		if !expected[p] {
+			t.Errorf("unexpected %q", p)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package sqlarfs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// LayerSpec is a layer of an FS returned by [UnionWithOptions]: an archive with
+// the options of its own [FS] (ex: a permission mask).
+type LayerSpec struct {
+	DB      *sql.DB
+	Options []Option
+}
+
+// UnionWithOptions returns an [io/fs.FS] that presents the merged content of multiple archives,
+// each with its own options. layers[0] is the top layer: an entry is looked up in each
+// layer in order, and the first layer in which it exists governs access to it, with its own
+// permission rules (ex: a world-readable base layer with an overlay restricted to [PermOthers]).
+// An entry that exists but is not readable in a layer is not looked up in the next layers.
+//
+// The listing of a directory merges the listings of that directory in all the layers in
+// which it is a directory, an entry of an upper layer hiding the entries with the same name
+// in lower layers.
+//
+// UnionWithOptions panics if layers is empty.
+func UnionWithOptions(layers []LayerSpec) fs.FS {
+	if len(layers) == 0 {
+		panic(fmt.Errorf("sqlarfs.UnionWithOptions: no layers"))
+	}
+	u := &unionFS{layers: make([]FS, len(layers))}
+	for i, l := range layers {
+		u.layers[i] = New(l.DB, l.Options...)
+	}
+	return u
+}
+
+type unionFS struct {
+	layers []FS // Top layer first
+}
+
+var (
+	_ fs.StatFS    = (*unionFS)(nil)
+	_ fs.ReadDirFS = (*unionFS)(nil)
+)
+
+// Open implements interface [fs.FS].
+func (u *unionFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, l := range u.layers {
+		f, err := l.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, isDir := f.(*dir); isDir {
+			return &unionDir{File: f, fs: u, name: name}, nil
+		}
+		return f, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat implements interface [fs.StatFS].
+func (u *unionFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, l := range u.layers {
+		info, err := l.Stat(name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return info, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements interface [fs.ReadDirFS].
+func (u *unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	var list []fs.DirEntry
+	seen := make(map[string]bool)
+	found := false
+	for _, l := range u.layers {
+		entries, err := l.ReadDir(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			if !found {
+				// The top-most layer governs
+				return nil, err
+			}
+			// Hidden by an upper layer
+			continue
+		}
+		found = true
+		for _, e := range entries {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				list = append(list, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+// unionDir is a directory of a [unionFS]: its metadata comes from the top-most layer,
+// its entries from all the layers.
+type unionDir struct {
+	fs.File
+	fs      *unionFS
+	name    string
+	entries []fs.DirEntry // nil until the first call to ReadDir
+}
+
+// ReadDir implements interface [fs.ReadDirFile].
+func (d *unionDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		entries, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = append(entries, nil)[:len(entries)] // Not nil, even if empty
+	}
+	if n <= 0 {
+		list := d.entries
+		d.entries = d.entries[len(d.entries):]
+		return list, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	list := d.entries[:n:n]
+	d.entries = d.entries[n:]
+	return list, nil
+}
@@ -0,0 +1,75 @@
+package sqlarfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestUnionWithOptions(t *testing.T) {
+	base := createDB(t, sqlarSchema)
+	insertEntries(t, base,
+		entry{name: "secret.txt", mode: syscall.S_IFREG | 0600, sz: 4, data: []byte("base")},
+		entry{name: "private.txt", mode: syscall.S_IFREG | 0600, sz: 4, data: []byte("base")},
+		entry{name: "dir/base.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("base")},
+		entry{name: "dir/both.txt", mode: syscall.S_IFREG | 0644, sz: 4, data: []byte("base")},
+	)
+	overlay := createDB(t, sqlarSchema)
+	insertEntries(t, overlay,
+		entry{name: "secret.txt", mode: syscall.S_IFREG | 0600, sz: 7, data: []byte("overlay")},
+		entry{name: "dir/both.txt", mode: syscall.S_IFREG | 0644, sz: 7, data: []byte("overlay")},
+		entry{name: "dir/overlay.txt", mode: syscall.S_IFREG | 0644, sz: 7, data: []byte("overlay")},
+	)
+
+	// Open overlay on top of a base restricted to the permissions of others
+	u := sqlarfs.UnionWithOptions([]sqlarfs.LayerSpec{
+		{DB: overlay},
+		{DB: base, Options: []sqlarfs.Option{sqlarfs.PermOthers}},
+	})
+	for name, expected := range map[string]string{
+		"secret.txt":      "overlay",
+		"dir/both.txt":    "overlay",
+		"dir/base.txt":    "base",
+		"dir/overlay.txt": "overlay",
+	} {
+		b, err := fs.ReadFile(u, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(b) != expected {
+			t.Errorf("%s: got %q, expected %q", name, b, expected)
+		}
+	}
+	if _, err := fs.ReadFile(u, "private.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("private.txt: got %v, expected ErrPermission", err)
+	}
+	if _, err := fs.Stat(u, "missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing.txt: got %v, expected ErrNotExist", err)
+	}
+	entries, err := fs.ReadDir(u, "dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := names(entries), "base.txt both.txt overlay.txt"; got != expected {
+		t.Errorf("dir: got %q, expected %q", got, expected)
+	}
+	if err := fstest.TestFS(sqlarfs.UnionWithOptions([]sqlarfs.LayerSpec{{DB: overlay}, {DB: base}}),
+		"secret.txt", "private.txt", "dir/base.txt", "dir/both.txt", "dir/overlay.txt"); err != nil {
+		t.Error(err)
+	}
+
+	// The restricted layer on top hides the file of the open layer
+	u = sqlarfs.UnionWithOptions([]sqlarfs.LayerSpec{
+		{DB: base, Options: []sqlarfs.Option{sqlarfs.PermOthers}},
+		{DB: overlay},
+	})
+	if _, err := fs.ReadFile(u, "secret.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("secret.txt: got %v, expected ErrPermission", err)
+	}
+	if b, err := fs.ReadFile(u, "dir/both.txt"); err != nil || string(b) != "base" {
+		t.Errorf("dir/both.txt: got %q, %v", b, err)
+	}
+}
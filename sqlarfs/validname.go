@@ -0,0 +1,17 @@
+package sqlarfs
+
+// sqlValidName is the SQL condition to select the entries whose name is a valid path
+// (see [io/fs.ValidPath]) other than the root ".".
+//
+// The names of a crafted archive ("../evil", "a/./b", "/etc/passwd"...) are not reachable
+// with Open or Stat, as their path is rejected by [io/fs.ValidPath], and this condition
+// ensures that they (and the directories implied by their paths) are never enumerated.
+// Such entries are reported by BrokenEntries.
+//
+// SQLite can't check that a name is valid UTF-8, so the listings also check the names
+// they return with [io/fs.ValidPath].
+const sqlValidName = `(name NOT IN ('','.','..')` +
+	` AND name NOT GLOB '/*' AND name NOT GLOB '*/'` +
+	` AND name NOT GLOB '*//*'` +
+	` AND name NOT GLOB './*' AND name NOT GLOB '*/./*' AND name NOT GLOB '*/.'` +
+	` AND name NOT GLOB '../*' AND name NOT GLOB '*/../*' AND name NOT GLOB '*/..')`
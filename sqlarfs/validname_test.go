@@ -0,0 +1,141 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+// TestInvalidNames checks that the entries of a crafted archive with names that are not
+// valid paths are never enumerated nor opened.
+func TestInvalidNames(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	content := []byte("evil\n")
+	insertEntries(t, db, entry{name: "a/ok.txt", mode: syscall.S_IFREG | 0644, sz: 3, data: []byte("ok\n")})
+	invalid := []string{
+		"../evil",
+		"a/../evil",
+		"a/..",
+		"./evil",
+		"a/./evil",
+		"a//evil",
+		"/evil",
+		"b/",
+		"c/../evil",
+		"evil\xff",
+		"d\xff/evil",
+	}
+	for _, name := range invalid {
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: int64(len(content)), data: content})
+	}
+	// Not readable: must not be reported by Inaccessible either
+	insertEntries(t, db, entry{name: "secret\xff", mode: syscall.S_IFREG, sz: int64(len(content)), data: content})
+	invalid = append(invalid, "secret\xff")
+	ar := newFS(db, sqlarfs.PermOwner)
+
+	if err := fstest.TestFS(ar, "a/ok.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != "." && path != "a" && path != "a/ok.txt" {
+			t.Errorf("unexpected entry %q", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{".", "a"} {
+		ar.DirEntries(dir)(func(e fs.DirEntry, err error) bool {
+			if err != nil {
+				t.Errorf("DirEntries(%q): %v", dir, err)
+			} else if name := e.Name(); name != "a" && name != "ok.txt" {
+				t.Errorf("DirEntries(%q): unexpected entry %q", dir, name)
+			}
+			return true
+		})
+		entries, _, err := ar.Page(dir, "", 100)
+		if err != nil {
+			t.Errorf("Page(%q): %v", dir, err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Page(%q): got %v", dir, names(entries))
+		}
+	}
+
+	tree, err := ar.Tree(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || len(tree["."]) != 1 || len(tree["a"]) != 1 {
+		t.Errorf("Tree: got %v", tree)
+	}
+
+	// "c" is only implied by an invalid name
+	for _, name := range append(invalid, "c", "..", "b") {
+		if f, err := ar.Open(strings.TrimSuffix(name, "/")); err == nil {
+			f.Close()
+			t.Errorf("Open(%q): error expected", name)
+		}
+	}
+
+	exists, err := ar.Exists([]string{"a", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists["a"] || exists["c"] {
+		t.Errorf("Exists: got %v", exists)
+	}
+
+	size, err := ar.DirSize("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 3 {
+		t.Errorf("DirSize: got %d, expected 3", size)
+	}
+
+	lazy := newFS(db, sqlarfs.PermOwner, sqlarfs.WithLazyReadDir())
+	if err := fstest.TestFS(lazy, "a/ok.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ar.ListBySize(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("ListBySize: got %d files", len(files))
+	}
+	exts, err := ar.Extensions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exts) != 1 || exts[".txt"] != 1 {
+		t.Errorf("Extensions: got %v", exts)
+	}
+	inaccessible, err := ar.Inaccessible()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inaccessible) != 0 {
+		t.Errorf("Inaccessible: got %q", inaccessible)
+	}
+
+	broken, err := ar.BrokenEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(broken) != len(invalid) {
+		t.Errorf("BrokenEntries: got %v", broken)
+	}
+}
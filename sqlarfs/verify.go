@@ -0,0 +1,157 @@
+package sqlarfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Mismatch is a difference between an archive and a directory reported by VerifyAgainstDir.
+type Mismatch struct {
+	Path   string
+	Reason string
+}
+
+// VerifyOption is a flag for VerifyAgainstDir.
+type VerifyOption uint
+
+const (
+	IgnoreMode    VerifyOption = 1 << iota // Do not compare permission bits
+	IgnoreModTime                          // Do not compare the modification time of regular files
+)
+
+// VerifyAgainstDir compares the content of ar (usually an [FS]) with the directory tree
+// dir on disk, to check a deployment. It reports, sorted by path, the entries that exist on only
+// one side, that have a different type, size or content, or (unless disabled with opts)
+// different permissions or modification time (truncated to the second).
+//
+// The content of regular files is compared in a streaming fashion.
+// Symbolic links on disk are not followed.
+func VerifyAgainstDir(ar fs.FS, dir string, opts ...VerifyOption) ([]Mismatch, error) {
+	var flags VerifyOption
+	for _, o := range opts {
+		flags |= o
+	}
+
+	var mismatches []Mismatch
+	report := func(path, format string, args ...any) {
+		mismatches = append(mismatches, Mismatch{Path: path, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]bool)
+	err := fs.WalkDir(ar, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		seen[path] = true
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		diskPath := filepath.Join(dir, filepath.FromSlash(path))
+		diskInfo, err := os.Lstat(diskPath)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			report(path, "only in archive")
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().Type() != diskInfo.Mode().Type() {
+			report(path, "type differs: %s in archive, %s on disk", info.Mode().Type(), diskInfo.Mode().Type())
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if flags&IgnoreMode == 0 && info.Mode().Perm() != diskInfo.Mode().Perm() {
+			report(path, "mode differs: %s in archive, %s on disk", info.Mode().Perm(), diskInfo.Mode().Perm())
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if flags&IgnoreModTime == 0 && info.ModTime().Unix() != diskInfo.ModTime().Unix() {
+			report(path, "modification time differs: %s in archive, %s on disk", info.ModTime(), diskInfo.ModTime())
+		}
+		if info.Size() != diskInfo.Size() {
+			report(path, "size differs: %d in archive, %d on disk", info.Size(), diskInfo.Size())
+			return nil
+		}
+		same, err := sameContent(ar, path, diskPath)
+		if err != nil {
+			return err
+		}
+		if !same {
+			report(path, "content differs")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = fs.WalkDir(os.DirFS(dir), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." || seen[path] {
+			return nil
+		}
+		report(path, "only on disk")
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Path < mismatches[j].Path
+	})
+	return mismatches, nil
+}
+
+// sameContent compares the content of file name in ar with the content of file diskPath.
+func sameContent(ar fs.FS, name string, diskPath string) (bool, error) {
+	f1, err := ar.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f1.Close()
+	f2, err := os.Open(diskPath)
+	if err != nil {
+		return false, err
+	}
+	defer f2.Close()
+
+	var buf1, buf2 [32 * 1024]byte
+	for {
+		n1, err1 := io.ReadFull(f1, buf1[:])
+		if err1 != nil && err1 != io.EOF && err1 != io.ErrUnexpectedEOF {
+			return false, err1
+		}
+		n2, err2 := io.ReadFull(f2, buf2[:])
+		if err2 != nil && err2 != io.EOF && err2 != io.ErrUnexpectedEOF {
+			return false, err2
+		}
+		if !bytes.Equal(buf1[:n1], buf2[:n2]) {
+			return false, nil
+		}
+		if err1 != nil || err2 != nil {
+			return err1 != nil && err2 != nil, nil
+		}
+	}
+}
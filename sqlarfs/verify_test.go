@@ -0,0 +1,64 @@
+package sqlarfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestVerifyAgainstDir(t *testing.T) {
+	ar := openFS(t, "testdata/dir.sqlar")
+	dir := t.TempDir()
+	if err := sqlarfs.Extract(ar, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches, err := sqlarfs.VerifyAgainstDir(ar, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("unexpected mismatches: %v", mismatches)
+	}
+
+	// Perturb the tree
+	cPath := filepath.Join(dir, "subdir", "c.txt")
+	c, err := os.ReadFile(cPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c[0] ^= 0xFF
+	info, _ := os.Stat(cPath)
+	if err := os.WriteFile(cPath, c, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extra.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches, err = sqlarfs.VerifyAgainstDir(ar, dir, sqlarfs.IgnoreModTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(mismatches)
+	expected := []string{"a.txt", "extra.txt", "subdir/c.txt"}
+	if len(mismatches) != len(expected) {
+		t.Fatalf("got %v, expected %q", mismatches, expected)
+	}
+	for i, m := range mismatches {
+		if m.Path != expected[i] {
+			t.Errorf("got %v, expected %q", m, expected[i])
+		}
+	}
+	if mismatches[2].Reason != "content differs" {
+		t.Errorf("subdir/c.txt: got %q", mismatches[2].Reason)
+	}
+}
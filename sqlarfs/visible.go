@@ -0,0 +1,24 @@
+package sqlarfs
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// ReadDirVisible is like ReadDir, but splits the entries of directory dir into visible
+// entries and hidden entries (dotfiles: names starting with "."), both sorted by name.
+// The split is computed from a single listing, so both lists are consistent.
+//
+// Only the names of the entries are considered: dir itself may be hidden, and the root
+// "." is not a dotfile.
+func (ar *arfs) ReadDirVisible(dir string) (visible, hidden []fs.DirEntry, err error) {
+	list, err := ar.ReadDir(dir)
+	for _, e := range list {
+		if strings.HasPrefix(e.Name(), ".") {
+			hidden = append(hidden, e)
+		} else {
+			visible = append(visible, e)
+		}
+	}
+	return visible, hidden, err
+}
@@ -0,0 +1,44 @@
+package sqlarfs_test
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestReadDirVisible(t *testing.T) {
+	db := createDB(t, sqlarSchema)
+	for _, name := range []string{".hidden", "a.txt", ".config/b.txt", "dir/.c.txt", "dir/d.txt", "dir/.e/f.txt"} {
+		insertEntries(t, db, entry{name: name, mode: syscall.S_IFREG | 0644, sz: 1, data: []byte("x")})
+	}
+	insertEntries(t, db, entry{name: "dir", mode: syscall.S_IFDIR | 0755})
+	ar := newFS(db)
+
+	for _, tc := range []struct {
+		dir             string
+		visible, hidden string
+	}{
+		{".", "a.txt dir", ".config .hidden"},
+		{"dir", "d.txt", ".c.txt .e"},
+		{".config", "b.txt", ""},
+		{"dir/.e", "f.txt", ""},
+	} {
+		visible, hidden, err := ar.ReadDirVisible(tc.dir)
+		if err != nil {
+			t.Errorf("%q: %v", tc.dir, err)
+			continue
+		}
+		if got := names(visible); got != tc.visible {
+			t.Errorf("%q: visible: got %q, expected %q", tc.dir, got, tc.visible)
+		}
+		if got := names(hidden); got != tc.hidden {
+			t.Errorf("%q: hidden: got %q, expected %q", tc.dir, got, tc.hidden)
+		}
+	}
+
+	if _, _, err := ar.ReadDirVisible("a.txt"); err == nil {
+		t.Error("a.txt: error expected")
+	}
+	if _, _, err := ar.ReadDirVisible("missing"); err == nil {
+		t.Error("missing: error expected")
+	}
+}
@@ -0,0 +1,25 @@
+package sqlarfs
+
+import "io/fs"
+
+// Warm fills the caches of metadata with a single scan of the archive, so that the
+// following lookups of existing entries with Stat and Open don't query the database.
+// This trades a startup cost for a predictable latency.
+//
+// The cache of the metadata of directories is always filled. The metadata of regular
+// files is cached only with [WithReadOnlyGuarantee]. Like with Tree, entries in directories
+// that can't be read are not cached.
+//
+// Warm only fills the caches: it is safe to call it concurrently with other methods.
+func (ar *arfs) Warm() error {
+	err := ar.walkTree(".", func(path string, fi *fileinfo) error {
+		if ar.readOnly && !fi.IsDir() {
+			ar.fileInfo.store(path, fi)
+		}
+		return nil
+	})
+	if err != nil {
+		return &fs.PathError{Op: "warm", Path: ".", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package sqlarfs_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+)
+
+func TestWarm(t *testing.T) {
+	var paths, dirs []string
+	fs.WalkDir(openFS(t, "testdata/dir.sqlar"), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	for _, tc := range []struct {
+		name  string
+		opts  []sqlarfs.Option
+		names []string
+	}{
+		{"read-only", []sqlarfs.Option{sqlarfs.WithReadOnlyGuarantee()}, paths},
+		{"default", nil, dirs},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, counter := openCountingDB(t, "testdata/dir.sqlar")
+			ar := newFS(db, tc.opts...)
+			if err := ar.Warm(); err != nil {
+				t.Fatal(err)
+			}
+			before := counter.queries.Load()
+			for _, name := range tc.names {
+				if _, err := ar.Stat(name); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			}
+			if n := counter.queries.Load() - before; n != 0 {
+				t.Errorf("%d queries after Warm, expected 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !cgo || modernc
+
+package webdavfs_test
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	if sqliteDriver == "" {
+		sqliteDriver = "sqlite"
+	}
+}
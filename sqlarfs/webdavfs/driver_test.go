@@ -0,0 +1,11 @@
+//go:build cgo && !modernc
+
+package webdavfs_test
+
+import _ "github.com/mattn/go-sqlite3"
+
+func init() {
+	if sqliteDriver == "" {
+		sqliteDriver = "sqlite3"
+	}
+}
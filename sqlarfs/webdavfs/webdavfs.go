@@ -0,0 +1,168 @@
+// Package webdavfs serves an [io/fs.FS], such as an SQLite Archive opened with
+// [github.com/dolmen-go/sqlar/sqlarfs], with WebDAV.
+//
+// It is a separate package so that the importers of sqlarfs don't depend on
+// [golang.org/x/net/webdav].
+package webdavfs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+
+	"golang.org/x/net/webdav"
+)
+
+// New returns a read-only [webdav.FileSystem] that serves the content of ar,
+// to mount an archive with WebDAV clients (such as the Finder of macOS) through
+// a [webdav.Handler].
+//
+// Files can only be opened for reading: other flags of OpenFile, Mkdir, RemoveAll and
+// Rename fail with [syscall.EROFS]. The files implement [io.Seeker] for Range requests:
+// if the files of ar implement [io.ReaderAt] (as those of sqlarfs.New do), the content is
+// read from that, otherwise the content of a file is loaded in memory when it is opened.
+//
+// If ar implements sqlarfs.ContextFS, files are opened with the context of the request.
+func New(ar fs.FS) webdav.FileSystem {
+	return &webdavFS{fsys: ar}
+}
+
+type webdavFS struct {
+	fsys fs.FS
+}
+
+// webdavName converts a name of [webdav.FileSystem], which is rooted at "/", to a path
+// of [fs.FS].
+func webdavName(name string) string {
+	name = path.Clean("/" + name)[1:]
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// Mkdir implements interface [webdav.FileSystem].
+func (w *webdavFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: syscall.EROFS}
+}
+
+// RemoveAll implements interface [webdav.FileSystem].
+func (w *webdavFS) RemoveAll(ctx context.Context, name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: syscall.EROFS}
+}
+
+// Rename implements interface [webdav.FileSystem].
+func (w *webdavFS) Rename(ctx context.Context, oldName, newName string) error {
+	return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: syscall.EROFS}
+}
+
+// Stat implements interface [webdav.FileSystem].
+func (w *webdavFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return fs.Stat(w.fsys, webdavName(name))
+}
+
+// OpenFile implements interface [webdav.FileSystem].
+func (w *webdavFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+	}
+	var (
+		f   fs.File
+		err error
+	)
+	if ar, ok := w.fsys.(interface {
+		OpenContext(context.Context, string) (fs.File, error)
+	}); ok {
+		f, err = ar.OpenContext(ctx, webdavName(name))
+	} else {
+		f, err = w.fsys.Open(webdavName(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	wf := &webdavFile{File: f, name: name}
+	if info.Mode().IsRegular() {
+		if r, ok := f.(io.ReaderAt); ok && hasSize(r, info.Size()) {
+			wf.content = io.NewSectionReader(r, 0, info.Size())
+		} else if r, ok := f.(io.ReadSeeker); ok {
+			wf.content = r
+		} else {
+			buf, err := io.ReadAll(f)
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+			wf.content = bytes.NewReader(buf)
+		}
+	}
+	return wf, nil
+}
+
+// hasSize returns true if the content read from r is size bytes long.
+// The size reported by Stat may be the size of the content before it is
+// transformed (see sqlarfs.WithReadTransform).
+func hasSize(r io.ReaderAt, size int64) bool {
+	var b [1]byte
+	if size > 0 {
+		if n, _ := r.ReadAt(b[:], size-1); n != 1 {
+			return false
+		}
+	}
+	n, err := r.ReadAt(b[:], size)
+	return n == 0 && err == io.EOF
+}
+
+// webdavFile is a file of a [webdavFS].
+type webdavFile struct {
+	fs.File
+	name    string
+	content io.ReadSeeker // nil for directories
+}
+
+// Read implements interface [webdav.File].
+func (f *webdavFile) Read(p []byte) (int, error) {
+	if f.content == nil {
+		return f.File.Read(p)
+	}
+	return f.content.Read(p)
+}
+
+// Seek implements interface [webdav.File].
+func (f *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	if f.content == nil {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	return f.content.Seek(offset, whence)
+}
+
+// Readdir implements interface [webdav.File].
+func (f *webdavFile) Readdir(count int) ([]fs.FileInfo, error) {
+	d, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+	}
+	entries, err := d.ReadDir(count)
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, err
+}
+
+// Write implements interface [webdav.File].
+func (f *webdavFile) Write(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: f.name, Err: syscall.EROFS}
+}
@@ -0,0 +1,142 @@
+package webdavfs_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/dolmen-go/sqlar/sqlarfs"
+	"github.com/dolmen-go/sqlar/sqlarfs/webdavfs"
+)
+
+var sqliteDriver string
+
+// createDB creates a temporary SQLite Archive with a directory and two files.
+func createDB(tb testing.TB, content string) *sql.DB {
+	tb.Helper()
+	db, err := sql.Open(sqliteDriver, "file:"+filepath.Join(tb.TempDir(), "test.sqlar"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			tb.Error("close archive DB:", err)
+		}
+	})
+
+	var compressed bytes.Buffer
+	fw, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	fw.Write([]byte(content))
+	fw.Close()
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		{`CREATE TABLE sqlar(name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB)`, nil},
+		{`INSERT INTO sqlar VALUES('dir',?,0,0,NULL)`, []any{syscall.S_IFDIR | 0755}},
+		{`INSERT INTO sqlar VALUES('dir/hello.txt',?,0,?,?)`, []any{syscall.S_IFREG | 0644, len(content), compressed.Bytes()}},
+		{`INSERT INTO sqlar VALUES('top.txt',?,0,3,'top')`, []any{syscall.S_IFREG | 0644}},
+	} {
+		if _, err := db.Exec(stmt.query, stmt.args...); err != nil {
+			tb.Fatalf("%s: %v", stmt.query, err)
+		}
+	}
+	return db
+}
+
+// serve serves fsys with WebDAV and returns a function that sends requests.
+func serve(t *testing.T, fsys webdav.FileSystem) func(method, path string, header map[string]string, body string) (int, string) {
+	srv := httptest.NewServer(&webdav.Handler{
+		FileSystem: fsys,
+		LockSystem: webdav.NewMemLS(),
+	})
+	t.Cleanup(srv.Close)
+
+	return func(method, path string, header map[string]string, body string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(b)
+	}
+}
+
+func TestNew(t *testing.T) {
+	content := strings.Repeat("Hello world\n", 100)
+	davFS := webdavfs.New(sqlarfs.New(createDB(t, content)))
+	do := serve(t, davFS)
+
+	code, body := do("PROPFIND", "/", map[string]string{"Depth": "1"}, "")
+	if code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND: got status %d", code)
+	}
+	for _, href := range []string{"<D:href>/dir/</D:href>", "<D:href>/top.txt</D:href>"} {
+		if !strings.Contains(body, href) {
+			t.Errorf("PROPFIND: %s missing from %s", href, body)
+		}
+	}
+
+	code, body = do(http.MethodGet, "/dir/hello.txt", nil, "")
+	if code != http.StatusOK || body != content {
+		t.Errorf("GET: got status %d, %q", code, body)
+	}
+	code, body = do(http.MethodGet, "/dir/hello.txt", map[string]string{"Range": "bytes=12-16"}, "")
+	if code != http.StatusPartialContent || body != "Hello" {
+		t.Errorf("GET Range: got status %d, %q", code, body)
+	}
+
+	for _, method := range []string{http.MethodPut, "MKCOL", http.MethodDelete} {
+		if code, _ := do(method, "/dir/new.txt", nil, "new"); code < 400 {
+			t.Errorf("%s: got status %d", method, code)
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := davFS.OpenFile(ctx, "/top.txt", os.O_RDWR, 0); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("OpenFile O_RDWR: got %v", err)
+	}
+	if err := davFS.Rename(ctx, "/top.txt", "/new.txt"); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Rename: got %v", err)
+	}
+}
+
+func TestNewTransform(t *testing.T) {
+	content := strings.Repeat("Hello world\n", 100)
+	// The transformed content is longer than the size of the file
+	ar := sqlarfs.New(createDB(t, content), sqlarfs.WithReadTransform(func(_ string, r io.Reader) (io.Reader, error) {
+		return io.MultiReader(r, strings.NewReader("more")), nil
+	}))
+	do := serve(t, webdavfs.New(ar))
+
+	if code, body := do(http.MethodGet, "/dir/hello.txt", nil, ""); code != http.StatusOK || body != content+"more" {
+		t.Errorf("GET: got status %d, %q", code, body)
+	}
+	if code, body := do(http.MethodGet, "/top.txt", nil, ""); code != http.StatusOK || body != "topmore" {
+		t.Errorf("GET: got status %d, %q", code, body)
+	}
+}